@@ -0,0 +1,437 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jcburley/gostd2joker/gen"
+)
+
+func runGenerate(args []string) error {
+	c := findCommand("generate")
+	o := &options{Options: gen.Options{Log: os.Stdout}}
+	mode := "fresh"
+	summary := false
+	fs := newFlagSet(c, o)
+	fs.Var(onceFlag{&o.JokerDir}, "joker", "")
+	fs.Var(choiceFlag{&mode, "overwrite"}, "overwrite", "")
+	fs.Var(choiceFlag{&mode, "replace"}, "replace", "")
+	fs.Var(choiceFlag{&mode, "fresh"}, "fresh", "")
+	fs.BoolVar(&summary, "summary", false, "")
+	args, err := parseFlags(c, fs, o, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usageErrorf("extraneous argument(s) starting with: %s", args[0])
+	}
+	if err := o.setUp(); err != nil {
+		return err
+	}
+
+	if o.JokerDir == "-" {
+		o.JokerDir = ""
+	}
+
+	if o.JokerDir != "" {
+		if _, e := os.Stat(filepath.Join(o.JokerDir, "main.go")); e != nil {
+			return usageErrorf("%s is not a Joker source directory: %s", o.JokerDir, e)
+		}
+		jokerLibDir := filepath.Join(o.JokerDir, "std", "go")
+		if mode == "replace" {
+			if e := os.RemoveAll(jokerLibDir); e != nil {
+				return fmt.Errorf("unable to effectively 'rm -fr %s'", jokerLibDir)
+			}
+		}
+
+		if mode != "overwrite" {
+			if _, e := os.Stat(jokerLibDir); e == nil || !os.IsNotExist(e) {
+				msg := "already exists"
+				if e != nil {
+					msg = e.Error()
+				}
+				return usageErrorf("refusing to populate existing directory %s; please 'rm -fr' first, or specify --overwrite or --replace: %s",
+					jokerLibDir, msg)
+			}
+			if e := os.MkdirAll(jokerLibDir, 0777); e != nil {
+				return fmt.Errorf("cannot 'mkdir -p %s': %s", jokerLibDir, e.Error())
+			}
+		}
+	}
+
+	res, err := o.run()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range res.Files {
+		if err := writeFile(f, o.Verbose); err != nil {
+			return err
+		}
+	}
+
+	if o.Verbose || summary {
+		printSummary(res)
+	}
+	return nil
+}
+
+// Writes f, announcing (if verbose) all but the generated package files.
+func writeFile(f *gen.OutputFile, verbose bool) error {
+	if verbose && f.Kind != gen.PackageFile {
+		if f.Note != "" {
+			fmt.Println(f.Note)
+		}
+		fmt.Printf("Writing %s\n", filepath.ToSlash(f.Path))
+	}
+	if e := os.MkdirAll(filepath.Dir(f.Path), 0777); e != nil {
+		return e
+	}
+	return ioutil.WriteFile(f.Path, []byte(f.Contents), f.Perm)
+}
+
+// Parses the options of a command that only reports on what would be
+// generated, returning its args.
+func parseReportFlags(name string, o *options, args []string) ([]string, error) {
+	c := findCommand(name)
+	return parseReportFlagSet(c, newFlagSet(c, o), o, args)
+}
+
+// Same as parseReportFlags, given a FlagSet with the command's own options.
+func parseReportFlagSet(c *command, fs *flag.FlagSet, o *options, args []string) ([]string, error) {
+	o.Log = ioutil.Discard // Else a "dry run" prints all the code
+	args, err := parseFlags(c, fs, o, args)
+	if err != nil {
+		return nil, err
+	}
+	if o.Verbose {
+		o.Log = os.Stderr
+	}
+	return args, nil
+}
+
+func runReport(args []string) error {
+	c := findCommand("report")
+	o := &options{}
+	save := ""
+	fs := newFlagSet(c, o)
+	fs.Var(onceFlag{&save}, "save", "")
+	args, err := parseReportFlagSet(c, fs, o, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usageErrorf("extraneous argument(s) starting with: %s", args[0])
+	}
+	if err := o.setUp(); err != nil {
+		return err
+	}
+	res, err := o.run()
+	if err != nil {
+		return err
+	}
+	printSummary(res)
+	if save != "" {
+		return saveResult(res, save)
+	}
+	return nil
+}
+
+func printSummary(res *gen.Result) {
+	generated := 0
+	for _, f := range res.Functions {
+		if f.Generated {
+			generated++
+		}
+	}
+	fmt.Printf("ABENDs:")
+	printAbends(res.Abends)
+	fmt.Printf("\nTotals: types=%d functions=%d methods=%d (%s%%) standalone=%d (%s%%) generated=%d (%s%%)\n",
+		res.Types, len(res.Functions)+res.Methods, res.Methods,
+		pct(res.Methods, len(res.Functions)+res.Methods),
+		len(res.Functions), pct(len(res.Functions), len(res.Functions)+res.Methods),
+		generated, pct(generated, len(res.Functions)))
+}
+
+// Describes whether f was generated, or why not.
+func functionStatus(f *gen.Function) string {
+	switch {
+	case f.Excluded:
+		return "excluded"
+	case f.Generated:
+		return "generated"
+	}
+	abends := []string{}
+	seen := map[string]bool{}
+	for _, a := range f.Abends {
+		if !seen[a] {
+			abends = append(abends, "ABEND"+a)
+			seen[a] = true
+		}
+	}
+	return strings.Join(abends, " ")
+}
+
+// Returns the type blocking what the (Unsupported) diagnostic
+// reports, qualified by its package's path where known, so types
+// whose packages share a name (text/template and html/template) are
+// told apart.
+func blockingType(d gen.Diagnostic) string {
+	if d.TypePath != "" {
+		return d.TypePath
+	}
+	return baseTypeName(d.Type)
+}
+
+// Returns the type named by a diagnostic's type, without any leading
+// '*' or "[]", so the reports attribute *T and []T to T.
+func baseTypeName(t string) string {
+	for {
+		switch {
+		case strings.HasPrefix(t, "*"):
+			t = t[1:]
+		case strings.HasPrefix(t, "[]"):
+			t = t[2:]
+		default:
+			return t
+		}
+	}
+}
+
+func runList(args []string) error {
+	o := &options{}
+	args, err := parseReportFlags("list", o, args)
+	if err != nil {
+		return err
+	}
+	what := "packages"
+	if len(args) > 0 && (args[0] == "packages" || args[0] == "functions") {
+		what, args = args[0], args[1:]
+	}
+	if err := o.setUp(); err != nil {
+		return err
+	}
+	res, err := o.run()
+	if err != nil {
+		return err
+	}
+
+	wanted := map[string]bool{}
+	for _, p := range args {
+		wanted[p] = true
+	}
+	found := map[string]bool{}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	if what == "packages" {
+		generated := map[string]int{}
+		total := map[string]int{}
+		for _, f := range res.Functions {
+			total[f.Package]++
+			if f.Generated {
+				generated[f.Package]++
+			}
+		}
+		for _, p := range res.Packages {
+			if len(wanted) == 0 || wanted[p.Path] {
+				found[p.Path] = true
+				fmt.Fprintf(w, "%s\t%d/%d\n", p.Path, generated[p.Path], total[p.Path])
+			}
+		}
+	} else {
+		for _, f := range res.Functions {
+			if len(wanted) == 0 || wanted[f.Package] {
+				found[f.Package] = true
+				fmt.Fprintf(w, "%s\t%s\n", f.Name, functionStatus(f))
+			}
+		}
+	}
+	w.Flush()
+
+	for _, p := range args {
+		if !found[p] {
+			return usageErrorf("no package %s found", p)
+		}
+	}
+	return nil
+}
+
+var abendCodeRegexp = regexp.MustCompile(`^(?:ABEND)?([0-9]+)$`)
+
+// How many of the functions affected by an ABEND code are listed (unless verbose).
+const maxExamples = 10
+
+func runExplain(args []string) error {
+	c := findCommand("explain")
+	o := &options{}
+	saved := ""
+	fs := newFlagSet(c, o)
+	fs.Var(onceFlag{&saved}, "result", "")
+	args, err := parseReportFlagSet(c, fs, o, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		for _, a := range gen.AbendCatalog() {
+			fmt.Fprintf(w, "ABEND%s\t%s\n", a.Code, a.Title)
+		}
+		return w.Flush()
+	}
+
+	codesOnly := true
+	for _, a := range args {
+		if m := abendCodeRegexp.FindStringSubmatch(a); m == nil {
+			codesOnly = false
+		} else if gen.LookupAbend(m[1]) == nil {
+			return usageErrorf("no such ABEND code %s (run 'gostd2joker explain' for a list of them)", a)
+		}
+	}
+	var res *gen.Result
+	run := "this run"
+	if saved != "" {
+		if o.GoDir != "" {
+			return usageErrorf("specify --go or --result, not both")
+		}
+		if res, err = readResult(saved); err != nil {
+			return err
+		}
+		run = saved
+	} else if _, e := os.Stat("GO.link"); !codesOnly || o.GoDir != "" || e == nil { // Codes are explained even without a Go source tree
+		if err := o.setUp(); err != nil {
+			return err
+		}
+		if res, err = o.run(); err != nil {
+			return err
+		}
+	}
+
+	functions := map[string]*gen.Function{}
+	if res != nil {
+		for _, f := range res.Functions {
+			functions[f.Name] = f
+		}
+	}
+	for i, a := range args {
+		if i > 0 {
+			fmt.Println()
+		}
+		if m := abendCodeRegexp.FindStringSubmatch(a); m != nil {
+			explainAbend(gen.LookupAbend(m[1]), res, run, o.Verbose)
+			continue
+		}
+		f, ok := functions[a]
+		if !ok {
+			return usageErrorf("no function %s found (specify it as <package-path>.<name>, e.g. net/url.Parse)", a)
+		}
+		fmt.Printf("%s (in %s): %s\n", f.Name, f.File, functionStatus(f))
+		for _, d := range res.Diagnostics {
+			if d.Function == f.Name {
+				fmt.Printf("  %s\n", diagnosticLine(d))
+			}
+		}
+		if f.JokerCode != "" {
+			fmt.Printf("\nJoker code:%s", f.JokerCode)
+		}
+		if f.GoCode != "" {
+			fmt.Printf("\nGo code:%s", f.GoCode)
+		}
+	}
+	return nil
+}
+
+func explainAbend(a *gen.AbendInfo, res *gen.Result, run string, verbose bool) {
+	fmt.Printf("ABEND%s: %s\n", a.Code, a.Title)
+	printWrapped("Triggered by: ", a.Construct)
+	if a.Config == "" {
+		printWrapped("Config override: ", "None, other than excluding the functions affected (via :exclude-functions).")
+	} else {
+		printWrapped("Config override: ", a.Config)
+	}
+	if a.Workaround != "" {
+		printWrapped("Otherwise: ", a.Workaround)
+	}
+	if res == nil {
+		printWrapped("", "(Specify --go or --result, or create ./GO.link, to see the functions it affects.)")
+		return
+	}
+
+	affected := []string{}
+	seen := map[string]bool{}
+	for _, d := range res.Diagnostics {
+		if d.Code != a.Code || seen[d.Function] {
+			continue
+		}
+		seen[d.Function] = true
+		s := d.Function
+		if d.Type != "" {
+			s += " (type " + d.Type + ")"
+		}
+		affected = append(affected, s)
+	}
+	fmt.Printf("  In %s: %d occurrence(s), preventing generation of %d function(s)", run, res.Abends[a.Code], len(affected))
+	if len(affected) == 0 {
+		fmt.Println()
+		return
+	}
+	if verbose || len(affected) <= maxExamples {
+		fmt.Println(":")
+	} else {
+		fmt.Println(", e.g.:")
+		affected = append(affected[:maxExamples], fmt.Sprintf("... and %d more (run with --verbose to list them all)", len(affected)-maxExamples))
+	}
+	for _, f := range affected {
+		fmt.Printf("    %s\n", f)
+	}
+}
+
+// Prints the label and text, wrapped (and indented) to fit in 80 columns.
+func printWrapped(label, text string) {
+	line := "  " + label
+	for i, w := range strings.Fields(text) {
+		switch {
+		case i == 0:
+			line += w
+		case len(line)+1+len(w) > 80:
+			fmt.Println(line)
+			line = "    " + w
+		default:
+			line += " " + w
+		}
+	}
+	fmt.Println(line)
+}
+
+func printAbends(m map[string]int) {
+	type ac struct {
+		abendCode  string
+		abendCount int
+	}
+	a := []ac{}
+	for k, v := range m {
+		a = append(a, ac{abendCode: k, abendCount: v})
+	}
+	sort.Slice(a,
+		func(i, j int) bool {
+			if a[i].abendCount == a[j].abendCount {
+				return a[i].abendCode < a[j].abendCode
+			}
+			return a[i].abendCount > a[j].abendCount
+		})
+	for _, v := range a {
+		fmt.Printf(" %s(%d)", v.abendCode, v.abendCount)
+	}
+}
+
+func pct(i, j int) string {
+	if j == 0 {
+		return "--"
+	}
+	return fmt.Sprintf("%0.2f", (float64(i)/float64(j))*100.0)
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jcburley/gostd2joker/gen"
+)
+
+/* The coverage command reports, per package (and per type), which of
+/* the exported functions, methods, constants, and variables of the Go
+/* source tree would be wrapped, and why the others wouldn't be, as
+/* Markdown, JSON, or a self-contained HTML page. */
+
+type tally struct {
+	Total     int `json:"total"`
+	Generated int `json:"generated"`
+}
+
+func (t *tally) add(generated bool) {
+	t.Total++
+	if generated {
+		t.Generated++
+	}
+}
+
+func (t tally) String() string {
+	return fmt.Sprintf("%d/%d (%s%%)", t.Generated, t.Total, pct(t.Generated, t.Total))
+}
+
+type coverageCounts struct {
+	Functions tally `json:"functions"`
+	Methods   tally `json:"methods"`
+	Constants tally `json:"constants"`
+	Variables tally `json:"variables"`
+}
+
+func (c *coverageCounts) addAll(o coverageCounts) {
+	for _, p := range [][2]*tally{{&c.Functions, &o.Functions}, {&c.Methods, &o.Methods}, {&c.Constants, &o.Constants}, {&c.Variables, &o.Variables}} {
+		p[0].Total += p[1].Total
+		p[0].Generated += p[1].Generated
+	}
+}
+
+type coverageItem struct {
+	Name      string `json:"name"` // Within its package, e.g. "Parse" or "URL.String"
+	Generated bool   `json:"generated"`
+	Reason    string `json:"reason,omitempty"` // Why it wasn't generated
+}
+
+type typeCoverage struct {
+	Name      string `json:"name"` // Within its package, e.g. "URL"
+	Kind      string `json:"kind"` // E.g. "struct"
+	Predicate bool   `json:"predicate"`
+	Methods   tally  `json:"methods"`
+	Blocks    int    `json:"blocks"` // Functions not generated due (at least partly) to it
+}
+
+type packageCoverage struct {
+	Path      string          `json:"path"`
+	Counts    coverageCounts  `json:"counts"`
+	Functions []*coverageItem `json:"functions"`
+	Types     []*typeCoverage `json:"types"`
+	Methods   []*coverageItem `json:"methods"`
+	Constants []*coverageItem `json:"constants"`
+	Variables []*coverageItem `json:"variables"`
+}
+
+type coverageReport struct {
+	Version   string             `json:"version"`
+	Generated string             `json:"generated,omitempty"` // When, unless --no-timestamp
+	GoDir     string             `json:"goDir"`
+	Totals    coverageCounts     `json:"totals"`
+	Packages  []*packageCoverage `json:"packages"`
+}
+
+var declReasons = map[gen.DeclKind]string{
+	gen.Method:   "methods aren't wrapped",
+	gen.Constant: "constants aren't wrapped",
+	gen.Variable: "variables aren't wrapped",
+}
+
+// Explains why f wasn't generated, given the Unsupported diagnostics about it.
+func skipReason(f *gen.Function, diags []gen.Diagnostic) string {
+	if f.Excluded {
+		return "excluded by the config"
+	}
+	codes := []string{}
+	types := map[string][]string{}
+	seen := map[string]bool{} // Code and type, e.g. "883 url.Values"
+	for _, d := range diags {
+		if _, ok := types[d.Code]; !ok {
+			codes = append(codes, d.Code)
+			types[d.Code] = []string{}
+		}
+		if d.Type != "" && !seen[d.Code+" "+d.Type] {
+			seen[d.Code+" "+d.Type] = true
+			types[d.Code] = append(types[d.Code], d.Type)
+		}
+	}
+	reasons := []string{}
+	for _, c := range codes {
+		r := "ABEND" + c
+		if a := gen.LookupAbend(c); a != nil {
+			r += " " + a.Title
+		}
+		if len(types[c]) != 0 {
+			r += " (" + strings.Join(types[c], ", ") + ")"
+		}
+		reasons = append(reasons, r)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func newCoverageReport(o *options, res *gen.Result, wanted map[string]bool) *coverageReport {
+	r := &coverageReport{Version: gen.VERSION, GoDir: o.GoDir}
+	if !o.NoTimestamp {
+		r.Generated = time.Now().Format(time.RFC3339)
+	}
+	pkgs := map[string]*packageCoverage{}
+	pkg := func(p string) *packageCoverage {
+		pc, ok := pkgs[p]
+		if !ok {
+			pc = &packageCoverage{Path: p, Functions: []*coverageItem{}, Types: []*typeCoverage{},
+				Methods: []*coverageItem{}, Constants: []*coverageItem{}, Variables: []*coverageItem{}}
+			pkgs[p] = pc
+		}
+		return pc
+	}
+
+	diags := map[string][]gen.Diagnostic{}
+	blocks := map[string]map[string]bool{} // Types (e.g. "net/url.URL") to the functions they block
+	for _, d := range res.Diagnostics {
+		if d.Severity != gen.Unsupported {
+			continue
+		}
+		diags[d.Function] = append(diags[d.Function], d)
+		if d.Type != "" {
+			t := blockingType(d)
+			if blocks[t] == nil {
+				blocks[t] = map[string]bool{}
+			}
+			blocks[t][d.Function] = true
+		}
+	}
+
+	for _, f := range res.Functions {
+		if len(wanted) != 0 && !wanted[f.Package] {
+			continue
+		}
+		pc := pkg(f.Package)
+		item := &coverageItem{Name: strings.TrimPrefix(f.Name, f.Package+"."), Generated: f.Generated}
+		if !f.Generated {
+			item.Reason = skipReason(f, diags[f.Name])
+		}
+		pc.Functions = append(pc.Functions, item)
+		pc.Counts.Functions.add(f.Generated)
+	}
+	types := map[string]*typeCoverage{}
+	for _, t := range res.TypeDefs {
+		if len(wanted) != 0 && !wanted[t.Package] {
+			continue
+		}
+		pc := pkg(t.Package)
+		name := strings.TrimPrefix(t.Name, t.Package+".")
+		tc := &typeCoverage{Name: name, Kind: t.Kind, Predicate: t.Predicate, Blocks: len(blocks[t.Name])}
+		pc.Types = append(pc.Types, tc)
+		types[t.Name] = tc
+	}
+	for _, d := range res.Declarations {
+		if len(wanted) != 0 && !wanted[d.Package] {
+			continue
+		}
+		pc := pkg(d.Package)
+		item := &coverageItem{Name: strings.TrimPrefix(d.Name, d.Package+"."), Reason: declReasons[d.Kind]}
+		switch d.Kind {
+		case gen.Method:
+			pc.Methods = append(pc.Methods, item)
+			pc.Counts.Methods.add(false)
+			if tc, ok := types[d.Receiver]; ok {
+				tc.Methods.add(false)
+			}
+		case gen.Constant:
+			pc.Constants = append(pc.Constants, item)
+			pc.Counts.Constants.add(false)
+		case gen.Variable:
+			pc.Variables = append(pc.Variables, item)
+			pc.Counts.Variables.add(false)
+		}
+	}
+
+	paths := []string{}
+	for p, _ := range pkgs {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		r.Packages = append(r.Packages, pkgs[p])
+		r.Totals.addAll(pkgs[p].Counts)
+	}
+	return r
+}
+
+func runCoverage(args []string) error {
+	c := findCommand("coverage")
+	o := &options{}
+	format := "markdown"
+	output := ""
+	fs := newFlagSet(c, o)
+	fs.StringVar(&format, "format", format, "")
+	fs.Var(onceFlag{&output}, "output", "")
+	args, err := parseReportFlagSet(c, fs, o, args)
+	if err != nil {
+		return err
+	}
+	if format != "markdown" && format != "json" && format != "html" {
+		return usageErrorf("invalid value %q for --format: expected markdown, json, or html", format)
+	}
+	if err := o.setUp(); err != nil {
+		return err
+	}
+	res, err := o.run()
+	if err != nil {
+		return err
+	}
+
+	wanted := map[string]bool{}
+	for _, p := range args {
+		wanted[p] = true
+	}
+	r := newCoverageReport(o, res, wanted)
+	found := map[string]bool{}
+	for _, pc := range r.Packages {
+		found[pc.Path] = true
+	}
+	for _, p := range args {
+		if !found[p] {
+			return usageErrorf("no package %s found", p)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	switch format {
+	case "json":
+		by, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(by))
+		return err
+	case "html":
+		return coverageHTML.Execute(w, r)
+	}
+	writeCoverageMarkdown(w, r)
+	return nil
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "|", `\|`, "<", "&lt;", "`", "\\`", "[", `\[`)
+
+func md(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func writeCoverageMarkdown(w io.Writer, r *coverageReport) {
+	fmt.Fprintf(w, "# gostd2joker coverage of %s\n\n", md(r.GoDir))
+	fmt.Fprintf(w, "Generated by gostd2joker version %s", r.Version)
+	if r.Generated != "" {
+		fmt.Fprintf(w, " at %s", r.Generated)
+	}
+	fmt.Fprint(w, ".\n\n| Package | Functions | Methods | Constants | Variables |\n|---|---|---|---|---|\n")
+	for _, pc := range r.Packages {
+		fmt.Fprintf(w, "| [%s](#%s) | %s | %s | %s | %s |\n", md(pc.Path), anchor(pc.Path),
+			pc.Counts.Functions, pc.Counts.Methods, pc.Counts.Constants, pc.Counts.Variables)
+	}
+	fmt.Fprintf(w, "| **Total** | %s | %s | %s | %s |\n", r.Totals.Functions, r.Totals.Methods, r.Totals.Constants, r.Totals.Variables)
+
+	items := func(title string, is []*coverageItem) {
+		if len(is) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n### %s\n\n| Name | Status | Reason |\n|---|---|---|\n", title)
+		for _, i := range is {
+			status := "skipped"
+			if i.Generated {
+				status = "generated"
+			}
+			fmt.Fprintf(w, "| %s | %s | %s |\n", md(i.Name), status, md(i.Reason))
+		}
+	}
+	for _, pc := range r.Packages {
+		fmt.Fprintf(w, "\n## %s\n", md(pc.Path))
+		items("Functions", pc.Functions)
+		if len(pc.Types) != 0 {
+			fmt.Fprint(w, "\n### Types\n\n| Type | Kind | Methods | Predicate | Blocks (functions) |\n|---|---|---|---|---|\n")
+			for _, t := range pc.Types {
+				predicate := "no"
+				if t.Predicate {
+					predicate = "yes"
+				}
+				fmt.Fprintf(w, "| %s | %s | %s | %s | %d |\n", md(t.Name), t.Kind, t.Methods, predicate, t.Blocks)
+			}
+		}
+		items("Methods", pc.Methods)
+		items("Constants", pc.Constants)
+		items("Variables", pc.Variables)
+	}
+}
+
+// Returns the anchor (as GitHub, and the HTML page, generate it) for a heading.
+func anchor(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return -1
+	}, s)
+}
+
+var coverageHTML = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"anchor": anchor,
+	"items": func(title string, items []*coverageItem) interface{} {
+		return struct {
+			Title string
+			Items []*coverageItem
+		}{title, items}
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>gostd2joker coverage of {{.GoDir}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 0.5em 0 1em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+td.n { text-align: right; white-space: nowrap; }
+tr.generated td.status { color: #060; }
+tr.skipped td.status { color: #a00; }
+details { margin: 0.5em 0; }
+summary { cursor: pointer; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>gostd2joker coverage of {{.GoDir}}</h1>
+<p>Generated by gostd2joker version {{.Version}}{{with .Generated}} at {{.}}{{end}}.</p>
+<table>
+<tr><th>Package</th><th>Functions</th><th>Methods</th><th>Constants</th><th>Variables</th></tr>
+{{- range .Packages}}
+<tr><td><a href="#{{anchor .Path}}">{{.Path}}</a></td><td class="n">{{.Counts.Functions}}</td><td class="n">{{.Counts.Methods}}</td><td class="n">{{.Counts.Constants}}</td><td class="n">{{.Counts.Variables}}</td></tr>
+{{- end}}
+<tr><th>Total</th><th>{{.Totals.Functions}}</th><th>{{.Totals.Methods}}</th><th>{{.Totals.Constants}}</th><th>{{.Totals.Variables}}</th></tr>
+</table>
+{{- define "items"}}
+{{- if .Items}}
+<details><summary>{{.Title}} ({{len .Items}})</summary>
+<table>
+<tr><th>Name</th><th>Status</th><th>Reason</th></tr>
+{{- range .Items}}
+<tr class="{{if .Generated}}generated{{else}}skipped{{end}}"><td>{{.Name}}</td><td class="status">{{if .Generated}}generated{{else}}skipped{{end}}</td><td>{{.Reason}}</td></tr>
+{{- end}}
+</table>
+</details>
+{{- end}}
+{{- end}}
+{{- range .Packages}}
+<h2 id="{{anchor .Path}}">{{.Path}}</h2>
+{{- template "items" (items "Functions" .Functions)}}
+{{- if .Types}}
+<details><summary>Types ({{len .Types}})</summary>
+<table>
+<tr><th>Type</th><th>Kind</th><th>Methods</th><th>Predicate</th><th>Blocks (functions)</th></tr>
+{{- range .Types}}
+<tr><td>{{.Name}}</td><td>{{.Kind}}</td><td class="n">{{.Methods}}</td><td>{{if .Predicate}}yes{{else}}no{{end}}</td><td class="n">{{.Blocks}}</td></tr>
+{{- end}}
+</table>
+</details>
+{{- end}}
+{{- template "items" (items "Methods" .Methods)}}
+{{- template "items" (items "Constants" .Constants)}}
+{{- template "items" (items "Variables" .Variables)}}
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCoverageTypesSharingPackageName(t *testing.T) {
+	o := &options{}
+	o.GoDir = filepath.Join("tests", "samename")
+	if err := o.setUp(); err != nil {
+		t.Fatal(err)
+	}
+	res, err := o.run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int{}
+	for _, pc := range newCoverageReport(o, res, nil).Packages {
+		for _, tc := range pc.Types {
+			got[pc.Path+"."+tc.Name] = tc.Blocks
+		}
+	}
+	for typ, n := range map[string]int{"a/x.T": 2, "b/x.T": 1} {
+		if got[typ] != n {
+			t.Errorf("%s blocks %d function(s), not %d", typ, got[typ], n)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jcburley/gostd2joker/gen"
+)
+
+/* The diff command compares the APIs of two Go source trees (either
+/* of which may instead be a result saved by 'gostd2joker report
+/* --save'), listing the functions, types, methods, constants, and
+/* variables added (+), removed (-), or changed (~) in the new one;
+/* then how that affects the generated Joker namespaces: functions
+/* new to (+) or removed from (-) them, broken (!) by changes to their
+/* signatures, newly unlocked (^) or blocked (v), or whose generated
+/* code or ABENDs otherwise changed (~). */
+
+// Saves res, less its output files, as JSON to the file.
+func saveResult(res *gen.Result, file string) error {
+	r := *res
+	r.Files = nil
+	by, err := json.MarshalIndent(&r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(by, '\n'), 0666)
+}
+
+// Returns the result saved (by saveResult) in the file.
+func readResult(file string) (*gen.Result, error) {
+	by, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	res := &gen.Result{}
+	if err := json.Unmarshal(by, res); err != nil {
+		return nil, &gen.ParseError{Err: fmt.Errorf("%s is not a saved result: %s", file, err)}
+	}
+	return res, nil
+}
+
+// Returns the result saved in arg, if it's a file, else that of
+// running the generator on the Go source tree arg.
+func loadResult(o options, arg string) (*gen.Result, error) {
+	if si, err := os.Stat(arg); err == nil && !si.IsDir() {
+		return readResult(arg)
+	}
+	o.GoDir = arg
+	if err := o.setUp(); err != nil {
+		return nil, err
+	}
+	return o.run()
+}
+
+// Maps the qualified names of the functions, types, methods,
+// constants, and variables in res to their signatures.
+func apiSignatures(res *gen.Result) map[string]string {
+	sigs := map[string]string{}
+	for _, f := range res.Functions {
+		sigs[f.Name] = f.Signature
+	}
+	for _, t := range res.TypeDefs {
+		sigs[t.Name] = t.Definition
+	}
+	for _, d := range res.Declarations {
+		sigs[d.Name] = d.Signature
+	}
+	return sigs
+}
+
+var jokerParamRegexp = regexp.MustCompile(`\b_\w+`)
+
+// Returns the arglists in a (generated) defn, less the names of their
+// params (which callers don't see), e.g. "[^String _]".
+func jokerArglists(code string) string {
+	arglists := []string{}
+	for _, l := range strings.Split(code, "\n") {
+		l = strings.TrimPrefix(strings.TrimSpace(l), "(")
+		if strings.HasPrefix(l, "[") {
+			l = l[:strings.LastIndex(l, "]")+1]
+			arglists = append(arglists, jokerParamRegexp.ReplaceAllString(l, "_"))
+		}
+	}
+	return strings.Join(arglists, " ")
+}
+
+// Returns the name of f in its Joker namespace, e.g. "go.net.url/Parse".
+func jokerName(f *gen.Function) string {
+	if f.JokerName != "" {
+		return f.JokerName
+	}
+	// Saved by a version that didn't record it
+	return "go." + strings.Replace(f.Package, "/", ".", -1) + "/" + strings.TrimPrefix(f.Name, f.Package+".")
+}
+
+// A flag each use of which names another Go source tree (or saved result).
+type treesFlag struct {
+	p *[]string
+}
+
+func (f treesFlag) String() string {
+	if f.p == nil {
+		return ""
+	}
+	return strings.Join(*f.p, " ")
+}
+
+func (f treesFlag) Set(s string) error {
+	if s == "" {
+		return fmt.Errorf("missing value")
+	}
+	*f.p = append(*f.p, s)
+	return nil
+}
+
+// Returns the old and new Go source trees (or saved results) to
+// compare, given via --go or as arguments, in that order.
+func parseDiffArgs(o *options, args []string) ([]string, error) {
+	c := findCommand("diff")
+	fs := newFlagSet(c, o)
+	trees := []string{}
+	fs.Lookup("go").Value = treesFlag{&trees} // Rather than just one, as for the other commands
+	args, err := parseReportFlagSet(c, fs, o, args)
+	if err != nil {
+		return nil, err
+	}
+	trees = append(trees, args...)
+	if len(trees) != 2 {
+		return nil, usageErrorf("expected two Go source trees (or saved results), got %d", len(trees))
+	}
+	return trees, nil
+}
+
+func runDiff(args []string) error {
+	o := &options{}
+	trees, err := parseDiffArgs(o, args)
+	if err != nil {
+		return err
+	}
+
+	var results [2]*gen.Result
+	for i, arg := range trees {
+		if results[i], err = loadResult(*o, arg); err != nil {
+			return err
+		}
+	}
+	printDiff(os.Stdout, results[0], results[1])
+	return nil
+}
+
+// Prints how the API found, and what was generated, differ between
+// the old and new results.
+func printDiff(w io.Writer, oldRes, newRes *gen.Result) {
+	oldSigs, newSigs := apiSignatures(oldRes), apiSignatures(newRes)
+	names := []string{}
+	for n, _ := range newSigs {
+		names = append(names, n)
+	}
+	for n, _ := range oldSigs {
+		if _, ok := newSigs[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	added, removed, changed := 0, 0, 0
+	fmt.Fprintln(w, "Go API:")
+	for _, n := range names {
+		oldSig, inOld := oldSigs[n]
+		newSig, inNew := newSigs[n]
+		switch {
+		case !inOld:
+			fmt.Fprintf(w, "+ %s: %s\n", n, newSig)
+			added++
+		case !inNew:
+			fmt.Fprintf(w, "- %s: %s\n", n, oldSig)
+			removed++
+		case oldSig != newSig:
+			fmt.Fprintf(w, "~ %s: %s\n    was: %s\n", n, newSig, oldSig)
+			changed++
+		}
+	}
+
+	older := map[string]*gen.Function{}
+	for _, f := range oldRes.Functions {
+		older[f.Name] = f
+	}
+	names = []string{}
+	newer := map[string]*gen.Function{}
+	for _, f := range newRes.Functions {
+		newer[f.Name] = f
+		names = append(names, f.Name)
+	}
+	for n, _ := range older {
+		if _, ok := newer[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	gained, lost, broken, unlocked, blocked := 0, 0, 0, 0, 0
+	fmt.Fprintln(w, "\nJoker namespaces:")
+	for _, n := range names {
+		of, nf := older[n], newer[n]
+		switch {
+		case of == nil:
+			fmt.Fprintf(w, "+ %s: %s\n", jokerName(nf), functionStatus(nf))
+			if nf.Generated {
+				gained++
+			}
+		case nf == nil:
+			fmt.Fprintf(w, "- %s: was %s\n", jokerName(of), functionStatus(of))
+			if of.Generated {
+				lost++
+			}
+		case of.Excluded != nf.Excluded:
+			fmt.Fprintf(w, "~ %s: %s, was %s\n", jokerName(nf), functionStatus(nf), functionStatus(of))
+		case !of.Generated && nf.Generated:
+			fmt.Fprintf(w, "^ %s: generated, was %s\n", jokerName(nf), functionStatus(of))
+			unlocked++
+		case of.Generated && !nf.Generated:
+			fmt.Fprintf(w, "v %s: %s, was generated\n", jokerName(nf), functionStatus(nf))
+			blocked++
+		case nf.Generated && jokerArglists(of.JokerCode) != jokerArglists(nf.JokerCode):
+			fmt.Fprintf(w, "! %s: %s, was %s\n", jokerName(nf), jokerArglists(nf.JokerCode), jokerArglists(of.JokerCode))
+			broken++
+		case nf.Generated && (of.JokerCode != nf.JokerCode || of.GoCode != nf.GoCode):
+			fmt.Fprintf(w, "~ %s: generated code changed\n", jokerName(nf))
+		case !nf.Generated && functionStatus(of) != functionStatus(nf):
+			fmt.Fprintf(w, "~ %s: %s, was %s\n", jokerName(nf), functionStatus(nf), functionStatus(of))
+		}
+	}
+
+	fmt.Fprintf(w, "\nGo API: %d added, %d removed, %d changed; Joker: %d new, %d removed, %d broken, %d unlocked, %d blocked\n",
+		added, removed, changed, gained, lost, broken, unlocked, blocked)
+}
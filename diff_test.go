@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// (tests/gold/*/diff.gold covers the differences found, between trees
+// and saved results.)
+
+func TestDiffArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args  []string
+		trees []string // Or nil, if a usage error
+	}{
+		{[]string{"old", "new"}, []string{"old", "new"}},
+		{[]string{"--go", "old", "--go", "new"}, []string{"old", "new"}},
+		{[]string{"--go", "old", "new"}, []string{"old", "new"}},
+		{[]string{"-v", "--go=old", "--", "new"}, []string{"old", "new"}},
+		{[]string{"--go", "old"}, nil},
+		{[]string{"--go", "old", "--go", "new", "newer"}, nil},
+		{[]string{"--go", "", "new"}, nil},
+	} {
+		trees, err := parseDiffArgs(&options{}, tc.args)
+		if tc.trees == nil {
+			if code := exitCode(err); err == nil || code != exitUsage {
+				t.Errorf("%q: got %q (exit code %d), not a usage error", tc.args, trees, code)
+			}
+		} else if err != nil || !reflect.DeepEqual(trees, tc.trees) {
+			t.Errorf("%q: got %q (%v), not %q", tc.args, trees, err, tc.trees)
+		}
+	}
+}
+
+func TestDiffNotSavedResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gostd2joker-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bad := filepath.Join(dir, "bad.json")
+	if err := ioutil.WriteFile(bad, []byte("not JSON\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	_, err = loadResult(options{}, bad)
+	if err == nil {
+		t.Fatalf("loaded %s", bad)
+	}
+	if code := exitCode(err); code != exitParse {
+		t.Errorf("%s: exit code %d, not %d", err, code, exitParse)
+	}
+}
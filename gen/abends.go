@@ -0,0 +1,125 @@
+package gen
+
+import (
+	"sort"
+)
+
+/* Each Go construct that prevents a function from being wrapped is
+/* marked, in the function's (commented-out) code, by an ABEND code,
+/* and reported as an Unsupported Diagnostic with that code. This
+/* catalog explains the codes, e.g. for 'gostd2joker explain'. */
+
+// An AbendInfo describes an ABEND code.
+type AbendInfo struct {
+	Code       string // E.g. "883"
+	Title      string // What it means, in brief
+	Construct  string // The Go construct that triggers it
+	Config     string // How a Config override works around it, or "" if none can
+	Workaround string // Options (or other means) that may work around it, if any
+}
+
+const converterWorkaround = "A custom TypeConverter for the type (Options.Converters, when embedding the gen package)."
+
+var abendCatalog = map[string]*AbendInfo{}
+
+func init() {
+	for _, a := range []*AbendInfo{
+		{"042", "Result of an unknown type",
+			"A result (or field of a struct result) of a named type that neither has a Joker conversion nor is defined by the package (in the files built for the target), e.g. float64, uint64, complex128, uintptr, rune, or a type parameter.",
+			"",
+			"--max-depth <n>, if the type is that of a field nested more than <n> structs deep (it's then returned in a handle to the struct). " + converterWorkaround},
+		{"123", "Multiple results conveying no public information",
+			"A function returning more than one result, none of which converts to anything more useful than nil, e.g. pointers to structs with only unexported fields (io.Pipe).",
+			"",
+			""},
+		{"124", "Result conveying no public information",
+			"A function whose (sole) result converts to nothing more useful than nil, such as a struct with only unexported fields (reflect.Value, time.Time).",
+			"",
+			converterWorkaround},
+		{"401", "Pointer result",
+			"A function whose sole result is a pointer (e.g. *template.Template), which can't yet be returned as a value.",
+			"",
+			"--pointer-refs, which returns such results as references to the (live) Go values."},
+		{"675", "No results to return",
+			"A function that returns nothing, so would be called only for its side effects (which is supported only for functions called in goroutines, or calling back into Joker).",
+			"Naming a pointer param, through which the function returns a result, in :out-params.",
+			"--async <function>, which calls it in a goroutine, returning a future; or --out-param <function>:<param>, like :out-params."},
+		{"712", "Unnamed param",
+			"A param lacking a name where one's needed in the Go wrapper's param list. (Not expected to happen.)",
+			"",
+			""},
+		{"713", "Unnamed param",
+			"A param lacking a name where one's needed in the wrapped function's arg list. (Not expected to happen.)",
+			"",
+			""},
+		{"731", "Unsupported out-parameter",
+			"An out-parameter (per :out-params, --out-param, or the built-in defaults, such as flag.IntVar's p) that is neither an interface{} (as for json.Unmarshal's v) nor a pointer to a type the Go wrapper can declare: a predeclared type, or an exported type defined by the package or by a package its file imports (e.g. *time.Duration).",
+			"Omitting the param from the function's :out-params (e.g. :out-params [] to have none).",
+			"--out-param <function>: (with no params), which likewise has none."},
+		{"751", "Unsupported callback param type",
+			"A func-typed param (or one of a single-method interface type), passed a Joker fn, whose own params include a type the generated callback can't name, e.g. one from another package or a type parameter. (Even supported callbacks run only while the Joker thread waits on a Go call or on a future (via go.gostd/await or deref), not while the REPL is idle.)",
+			"",
+			""},
+		{"752", "Unsupported callback result type",
+			"A func-typed param (or one of a single-method interface type), passed a Joker fn, whose result is of a type to which Joker objects can't be converted.",
+			"",
+			""},
+		{"753", "Multiple callback results",
+			"A func-typed param (or one of a single-method interface type), passed a Joker fn, returning more than one result.",
+			"",
+			""},
+		{"754", "Interface not implementable by a Joker fn",
+			"A param of a single-method interface type (e.g. io.Reader, http.Handler) whose method hits ABEND751, ABEND752, or ABEND753, so no adapter calling a Joker fn can be generated.",
+			"",
+			converterWorkaround},
+		{"881", "Unsupported param type",
+			"A param of a type (other than a named one) with neither a Joker type hint nor a conversion, e.g. a slice ([]byte), pointer, map, func, or a type from another package, as declared for Joker.",
+			"",
+			"--round-trip, if the type is (a pointer to) a struct defined by the package, whose params then accept maps and handles. " + converterWorkaround},
+		{"882", "Unsupported param type",
+			"The same as ABEND881, but in the param list of the Go wrapper (when one is generated).",
+			"",
+			"As for ABEND881."},
+		{"883", "Result of an unsupported type",
+			"A result (or field of a struct result) of a type with no Joker conversion: a map, an interface (other than error and the io streams), a func, a chan, a generic type's instantiation, or a type from another package (time.Time).",
+			"",
+			"--max-depth <n>, if the type is that of a field nested more than <n> structs deep (it's then returned in a handle to the struct). " + converterWorkaround},
+		{"884", "Unsupported named param type",
+			"The same as ABEND885, but in the param list of the Go wrapper (when one is generated).",
+			"",
+			"As for ABEND885."},
+		{"885", "Unsupported named param type",
+			"A param of a named type with no Joker type hint, e.g. float64, int64, rune, or a type defined by the package, as declared for Joker.",
+			"",
+			"--round-trip, if the type is a struct defined by the package, whose params then accept maps and handles. " + converterWorkaround},
+		{"922", "Unnamed param",
+			"A param lacking a name where one's needed in the Go call made by any of a function's arities (as listed in its :go code). (Not expected to happen, as Go's parser never yields a nil name.)",
+			"",
+			""},
+		{"947", "Recursive type",
+			"A result whose type refers, directly or via other types, to itself (e.g. http.Request, via its Response field), so can't be converted to a (finite) map.",
+			"",
+			"--max-depth <n>, which returns structs nested more than <n> deep as handles, so the recursion isn't followed. " + converterWorkaround},
+	} {
+		abendCatalog[a.Code] = a
+	}
+}
+
+// Returns the description of the ABEND code (e.g. "883"), or nil if there's no such code.
+func LookupAbend(code string) *AbendInfo {
+	return abendCatalog[code]
+}
+
+// Returns the descriptions of all the ABEND codes, in order.
+func AbendCatalog() []*AbendInfo {
+	codes := []string{}
+	for c, _ := range abendCatalog {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	a := []*AbendInfo{}
+	for _, c := range codes {
+		a = append(a, abendCatalog[c])
+	}
+	return a
+}
@@ -0,0 +1,431 @@
+package gen
+
+import (
+	"fmt"
+	. "go/ast"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+/* A Config holds per-project policy, normally read (via LoadConfig)
+/* from an EDN file such as:
+
+  ;; gostd2joker.edn
+  {:exclude-dirs ["syscall"]                ; Directory names skipped wherever found (besides builtin, cmd, etc.)
+   :exclude-packages ["net/http/httptest"]  ; Packages not wrapped
+   :include-packages ["net/url" "strings"]  ; If given, the only packages wrapped
+   :exclude-functions ["strings.Title"]     ; Functions not wrapped
+   :include-functions []                    ; If nonempty, the only functions wrapped
+   :exclude-types ["uint64" "net/url.Userinfo"]  ; Functions taking or returning these Go types aren't wrapped
+   :namespace-prefix "go"                   ; Of the Joker namespaces (the default, as in go.net.url)
+   :functions {"net/url.Parse" {:rename "parse-url"  ; Joker name (instead of Parse)
+                                :errors :throw       ; Or :return, overriding --errors-as-exceptions[-for]
+                                :results :map        ; Or :vector, for multiple results (and out-params)
+                                :out-params ["p"]    ; Replacing any others (--out-param still adds to them)
+                                :doc "Extra text for the docstring."}}}
+
+/* Packages, functions, and types may be named by string or symbol,
+/* types as for Options.Converters (e.g. "uint64", "net/url.Userinfo",
+/* or, for a type defined by a package not wrapped, "uuid.UUID"). Entries
+/* naming packages, functions, types, or params that weren't found in
+/* the Go source tree are reported as warnings. */
+
+type Config struct {
+	File             string // Where it was read from, for diagnostics
+	ExcludeDirs      []string
+	ExcludePackages  []string
+	IncludePackages  []string
+	ExcludeFunctions []string
+	IncludeFunctions []string
+	ExcludeTypes     []string
+	NamespacePrefix  string                     // E.g. "go" (the default), for go.net.url
+	Functions        map[string]*FunctionConfig // Qualified function names (net/url.Parse) to overrides
+}
+
+type FunctionConfig struct {
+	Rename    string   // Joker name, if not the Go name
+	Errors    string   // "throw" or "return", if not per Options
+	Results   string   // "map" or "vector" (the default), for multiple results
+	OutParams []string // Names of pointer params that return results, if any
+	Doc       string   // Appended to the Go function's doc comment
+}
+
+// Reads the Config in the named EDN file.
+func LoadConfig(file string) (*Config, error) {
+	by, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, &ParseError{err}
+	}
+	return ParseConfig(file, string(by))
+}
+
+// Parses text, a Config in EDN form, noting (for errors) that it was read from file.
+func ParseConfig(file, text string) (*Config, error) {
+	c, err := parseConfig(file, text)
+	if err != nil {
+		return nil, &ParseError{err}
+	}
+	return c, nil
+}
+
+func parseConfig(file, text string) (*Config, error) {
+	v, err := readEDN(file, text)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(ednMap)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a map, not %s", file, ednString(v))
+	}
+	c := &Config{File: file, Functions: map[string]*FunctionConfig{}}
+	for _, e := range m {
+		var err error
+		switch e.key {
+		case ednKeyword("exclude-dirs"):
+			c.ExcludeDirs, err = ednNames(e.value)
+		case ednKeyword("exclude-packages"):
+			c.ExcludePackages, err = ednNames(e.value)
+		case ednKeyword("include-packages"):
+			c.IncludePackages, err = ednNames(e.value)
+		case ednKeyword("exclude-functions"):
+			c.ExcludeFunctions, err = ednNames(e.value)
+		case ednKeyword("include-functions"):
+			c.IncludeFunctions, err = ednNames(e.value)
+		case ednKeyword("exclude-types"):
+			c.ExcludeTypes, err = ednNames(e.value)
+		case ednKeyword("namespace-prefix"):
+			c.NamespacePrefix, err = ednName(e.value)
+			if err == nil && !isNamespacePrefix(c.NamespacePrefix) {
+				err = fmt.Errorf("invalid namespace prefix %s", ednString(e.value))
+			}
+		case ednKeyword("functions"):
+			if err := c.parseFunctions(file, e.line, e.value); err != nil {
+				return nil, err // Already locating the error
+			}
+		default:
+			err = fmt.Errorf("unrecognized key %s", ednString(e.key))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", file, e.line, err)
+		}
+	}
+	return c, nil
+}
+
+func (c *Config) parseFunctions(file string, line int, v interface{}) error {
+	m, ok := v.(ednMap)
+	if !ok {
+		return fmt.Errorf("%s:%d: expected a map of functions, not %s", file, line, ednString(v))
+	}
+	for _, e := range m {
+		f, err := ednName(e.key)
+		if err == nil {
+			c.Functions[f], err = parseFunctionConfig(e.value)
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s", file, e.line, err)
+		}
+	}
+	return nil
+}
+
+func parseFunctionConfig(v interface{}) (*FunctionConfig, error) {
+	m, ok := v.(ednMap)
+	if !ok {
+		return nil, fmt.Errorf("expected a map of overrides, not %s", ednString(v))
+	}
+	fc := &FunctionConfig{}
+	for _, e := range m {
+		var err error
+		switch e.key {
+		case ednKeyword("rename"):
+			fc.Rename, err = ednName(e.value)
+			if err == nil && !isJokerSymbol(fc.Rename) {
+				err = fmt.Errorf("invalid Joker name %s", ednString(e.value))
+			}
+		case ednKeyword("errors"):
+			fc.Errors, err = ednChoice(e.value, "throw", "return")
+		case ednKeyword("results"):
+			fc.Results, err = ednChoice(e.value, "map", "vector")
+		case ednKeyword("out-params"):
+			fc.OutParams, err = ednNames(e.value)
+		case ednKeyword("doc"):
+			var ok bool
+			if fc.Doc, ok = e.value.(string); !ok {
+				err = fmt.Errorf("expected a string, not %s", ednString(e.value))
+			}
+		default:
+			err = fmt.Errorf("unrecognized key %s", ednString(e.key))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fc, nil
+}
+
+// Returns v, a string or symbol, as a string.
+func ednName(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case ednSymbol:
+		return string(v), nil
+	}
+	return "", fmt.Errorf("expected a string or symbol, not %s", ednString(v))
+}
+
+// Returns v, a vector of strings or symbols, as strings.
+func ednNames(v interface{}) ([]string, error) {
+	s, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a vector, not %s", ednString(v))
+	}
+	names := []string{}
+	for _, e := range s {
+		n, err := ednName(e)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, n)
+	}
+	return names, nil
+}
+
+// Returns v, a keyword, as one of the choices.
+func ednChoice(v interface{}, choices ...string) (string, error) {
+	if k, ok := v.(ednKeyword); ok {
+		for _, c := range choices {
+			if string(k) == c {
+				return c, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("expected :%s, not %s", strings.Join(choices, " or :"), ednString(v))
+}
+
+func isJokerSymbol(s string) bool {
+	if s == "" || strings.ContainsAny(s[0:1], "0123456789:#'") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if isEDNDelimiter(s[i]) || s[i] == '/' {
+			return false
+		}
+	}
+	return true
+}
+
+// Whether s, e.g. "go" or "my.go", can start the names of Joker namespaces.
+func isNamespacePrefix(s string) bool {
+	for _, seg := range strings.Split(s, ".") {
+		if !isJokerSymbol(seg) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sets up the policy given by c.
+func (g *Generator) applyConfig(c *Config) {
+	for _, d := range c.ExcludeDirs {
+		g.excludeDirs[d] = true
+	}
+	for f, fc := range c.Functions {
+		if fc.OutParams != nil {
+			g.outParams[f] = append([]string{}, fc.OutParams...)
+		}
+	}
+}
+
+func (g *Generator) functionConfig(f string) *FunctionConfig {
+	if c := g.opts.Config; c != nil {
+		if fc, ok := c.Functions[f]; ok {
+			return fc
+		}
+	}
+	return &FunctionConfig{}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the package (its relative, Unix-style path) is to be wrapped.
+func (g *Generator) packageWanted(pkgDirUnix string) bool {
+	c := g.opts.Config
+	if c == nil {
+		return true
+	}
+	if contains(c.ExcludePackages, pkgDirUnix) {
+		return false
+	}
+	return len(c.IncludePackages) == 0 || contains(c.IncludePackages, pkgDirUnix)
+}
+
+// Whether the (qualified) function is to be wrapped.
+func (g *Generator) functionWanted(f string) bool {
+	c := g.opts.Config
+	if c == nil {
+		return true
+	}
+	if contains(c.ExcludeFunctions, f) || g.typeExcluded[f] {
+		return false
+	}
+	return len(c.IncludeFunctions) == 0 || contains(c.IncludeFunctions, f)
+}
+
+// Returns the Joker namespace of the package (its relative, Unix-style
+// path), e.g. "go.net.url". (That of the support package, go.gostd,
+// is fixed.)
+func (g *Generator) namespace(pkgDirUnix string) string {
+	prefix := "go"
+	if c := g.opts.Config; c != nil && c.NamespacePrefix != "" && pkgDirUnix != runtimePkg {
+		prefix = c.NamespacePrefix
+	}
+	return prefix + "." + strings.Replace(pkgDirUnix, "/", ".", -1)
+}
+
+// Returns the Joker name of the (qualified) function.
+func (g *Generator) jokerName(f string, fn *funcInfo) string {
+	if r, ok := g.renames[f]; ok {
+		return r
+	}
+	return fn.fd.Name.Name
+}
+
+// Warns of config entries naming things not found in the Go source tree.
+func (g *Generator) validateConfig() {
+	c := g.opts.Config
+	if c == nil {
+		return
+	}
+	warn := func(format string, args ...interface{}) {
+		g.diagnose(Warning, c.File, fmt.Sprintf(format, args...))
+	}
+	for _, d := range c.ExcludeDirs {
+		if !g.dirNamesSeen[d] {
+			warn("No directory named %s found", d)
+		}
+	}
+	for _, p := range append(append([]string{}, c.ExcludePackages...), c.IncludePackages...) {
+		if !g.dirsSeen[p] {
+			warn("No package %s found", p)
+		}
+	}
+	for _, f := range append(append([]string{}, c.ExcludeFunctions...), c.IncludeFunctions...) {
+		if _, ok := g.qualifiedFunctions[f]; !ok {
+			warn("No function %s found (in the packages wrapped)", f)
+		}
+	}
+	if len(c.ExcludeTypes) != 0 {
+		typesSeen := map[string]bool{}
+		sortedFuncInfoMap(g.qualifiedFunctions,
+			func(f string, fn *funcInfo) {
+				for _, t := range signatureTypes(fn) {
+					typesSeen[t] = true
+					if contains(c.ExcludeTypes, t) {
+						g.typeExcluded[f] = true
+					}
+				}
+			})
+		for _, t := range c.ExcludeTypes {
+			if !typesSeen[t] {
+				warn("No function taking or returning %s found (in the packages wrapped)", t)
+			}
+		}
+	}
+
+	var functions []string
+	for f, _ := range c.Functions {
+		functions = append(functions, f)
+	}
+	sort.Strings(functions)
+	jokerNames := map[string][]string{} // Qualified Joker names to the functions they'd name
+	sortedFuncInfoMap(g.qualifiedFunctions,
+		func(f string, fn *funcInfo) {
+			if !g.functionWanted(f) {
+				return
+			}
+			name := fn.pkgDirUnix + "." + fn.fd.Name.Name
+			if r := g.functionConfig(f).Rename; r != "" {
+				name = fn.pkgDirUnix + "." + r
+			}
+			jokerNames[name] = append(jokerNames[name], f)
+		})
+	for _, f := range functions {
+		fc := c.Functions[f]
+		fn, ok := g.qualifiedFunctions[f]
+		if !ok {
+			warn("No function %s found (in the packages wrapped)", f)
+			continue
+		}
+		if fc.Rename != "" {
+			others := []string{}
+			for _, o := range jokerNames[fn.pkgDirUnix+"."+fc.Rename] {
+				if o != f {
+					others = append(others, o)
+				}
+			}
+			if len(others) != 0 {
+				warn("Not renaming %s to %s, which would also name %s", f, fc.Rename, strings.Join(others, ", "))
+			} else {
+				g.renames[f] = fc.Rename
+			}
+		}
+		for _, p := range fc.OutParams {
+			if t := paramType(fn.fd.Type.Params, p); t == nil {
+				warn("No param %s in %s", p, f)
+				g.invalidOutParams[f+":"+p] = true
+			} else if _, ok := t.(*StarExpr); !ok && !isDynamicOut(t) {
+				warn("Param %s of %s is neither a pointer nor an interface{}, so cannot be an out-param", p, f)
+				g.invalidOutParams[f+":"+p] = true
+			}
+		}
+	}
+}
+
+// Returns the names (as converterKey returns them) of the types the
+// function's params and results are of or are built from.
+func signatureTypes(fn *funcInfo) []string {
+	var ts []string
+	for _, fl := range []*FieldList{fn.fd.Type.Params, fn.fd.Type.Results} {
+		if fl == nil {
+			continue
+		}
+		for _, f := range fl.List {
+			Inspect(f.Type, func(n Node) bool {
+				switch n.(type) {
+				case *Ident, *SelectorExpr:
+					if t := converterKey(fn.pkgDirUnix, n.(Expr)); t != "" {
+						ts = append(ts, t)
+					}
+					return false
+				}
+				return true
+			})
+		}
+	}
+	return ts
+}
+
+// Returns the type of the named param in fl, or nil if there's none such.
+func paramType(fl *FieldList, name string) Expr {
+	if fl == nil {
+		return nil
+	}
+	for _, f := range fl.List {
+		for _, n := range f.Names {
+			if n.Name == name {
+				return f.Type
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package gen
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestReadEDN(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want string // ednString of the form read, or the error
+	}{
+		{`[a b]`, `[a b]`},
+		{`[a #_b]`, `[a]`},
+		{`[#_a]`, `[]`},
+		{`[#_ #_a b c]`, `[c]`},
+		{`(a #_(b c))`, `[a]`},
+		{`#{a #_b}`, `[a]`},
+		{`#_a b`, `b`},
+		{"b #_a ; Comment", `b`},
+		{`{:a 1 #_:b}`, `{:a 1}`},
+		{`{:a 1 #_:b #_2}`, `{:a 1}`},
+		{`{:a #_1 2}`, `{:a 2}`},
+		{`{:a nil, :b true, "c" false}`, `{:a nil, :b true, "c" false}`},
+		{`{:a 1 :a 2}`, `x.edn:1: duplicate key :a in map (first at line 1)`},
+		{"{:a 1\n :b {x 2\n     x 3}}", `x.edn:3: duplicate key x in map (first at line 2)`},
+		{`{:a 1 "a" 2 a 3}`, `{:a 1, "a" 2, a 3}`},
+		{`{:a}`, `x.edn:1: map literal has no value for key :a`},
+		{`"a\tb\\c\"d"`, `"a\tb\\c\"d"`},
+		{`"\n\r\b\f"`, `"\n\r\b\f"`},
+		{`"café"`, `"café"`},
+		{"\"two\nlines\"", `"two\nlines"`},
+		{`"\x41"`, `x.edn:1: invalid escape \x`},
+		{`"\a"`, `x.edn:1: invalid escape \a`},
+		{`"\'"`, `x.edn:1: invalid escape \'`},
+		{`"\u00"`, `x.edn:1: invalid escape \u00"`},
+		{`"\uzzzz"`, `x.edn:1: invalid escape \uzzzz`},
+		{`"abc`, `x.edn:1: unterminated string`},
+		{`[a #_]`, `x.edn:1: unmatched ']'`},
+		{`[a #_`, `x.edn:1: unexpected end of input`},
+		{`[a`, `x.edn:1: unexpected end of input`},
+		{`]`, `x.edn:1: unmatched ']'`},
+		{`a b`, `x.edn:1: unexpected 'b' after form`},
+		{`#inst "2020"`, `x.edn:1: unsupported dispatch after '#'`},
+		{`1.5`, `x.edn:1: unsupported literal 1.5`},
+	} {
+		got := ""
+		if v, err := readEDN("x.edn", tc.text); err != nil {
+			got = err.Error()
+		} else {
+			got = ednString(v)
+		}
+		if got != tc.want {
+			t.Errorf("readEDN(%q) = %s, not %s", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want string // The error, if any
+	}{
+		{`{:exclude-dirs ["syscall"] :functions {"net/url.Parse" {:rename "parse-url" :errors :throw}}}`, ""},
+		{`{:exclude-dirs ["syscall"] :exclude-dirs []}`, `x.edn:1: duplicate key :exclude-dirs in map (first at line 1)`},
+		{`{:exclude-dir ["syscall"]}`, `x.edn:1: unrecognized key :exclude-dir`},
+		{"{:functions\n {net/url.Parse {:errors :thrown}}}", `x.edn:2: expected :throw or :return, not :thrown`},
+		{`{:functions {net/url.Parse {:rename "a b"}}}`, `x.edn:1: invalid Joker name "a b"`},
+		{`{:exclude-types [uint64 "net/url.Userinfo"] :namespace-prefix my.go}`, ""},
+		{`{:exclude-types "uint64"}`, `x.edn:1: expected a vector, not "uint64"`},
+		{`{:namespace-prefix "go."}`, `x.edn:1: invalid namespace prefix "go."`},
+		{`{:namespace-prefix "go/x"}`, `x.edn:1: invalid namespace prefix "go/x"`},
+		{`[]`, `x.edn: expected a map, not []`},
+	} {
+		got := ""
+		if _, err := ParseConfig("x.edn", tc.text); err != nil {
+			got = err.Error()
+		}
+		if got != tc.want {
+			t.Errorf("ParseConfig(%q) got error %q, not %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want []string // Warnings, sorted
+	}{
+		{`{:exclude-packages ["net"] :functions {net/url.Parse {:rename "parse-url"}}}`, nil},
+		{`{:exclude-dirs ["nodir"]}`, []string{"No directory named nodir found"}},
+		{`{:include-packages ["net/nopkg"]}`, []string{"No package net/nopkg found"}},
+		{`{:exclude-functions ["net/url.NoFunc"]}`, []string{"No function net/url.NoFunc found (in the packages wrapped)"}},
+		{`{:exclude-types ["net/url.Userinfo" "complex64"]}`,
+			[]string{"No function taking or returning complex64 found (in the packages wrapped)"}},
+		{`{:functions {net/url.Parse {:rename "PathEscape"}}}`,
+			[]string{"Not renaming net/url.Parse to PathEscape, which would also name net/url.PathEscape"}},
+		{`{:functions {net/url.Parse {:out-params ["rawurl"]} net/url.User {:out-params ["nobody"]}}}`,
+			[]string{"No param nobody in net/url.User",
+				"Param rawurl of net/url.Parse is neither a pointer nor an interface{}, so cannot be an out-param"}},
+	} {
+		c, err := ParseConfig("x.edn", tc.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := New(Options{GoDir: filepath.Join("..", "tests", "small"), Config: c})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := g.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range res.Diagnostics {
+			if d.Severity == Warning && d.File == "x.edn" {
+				got = append(got, d.Message)
+			}
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("config %s warned %q, not %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestConfigPolicy(t *testing.T) {
+	c, err := ParseConfig("x.edn", `{:exclude-types ["net/url.Userinfo"] :namespace-prefix "my.go"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(Options{GoDir: filepath.Join("..", "tests", "small"), Config: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	functions := map[string]*Function{}
+	for _, f := range res.Functions {
+		functions[f.Name] = f
+	}
+	for _, tc := range []struct {
+		name      string
+		jokerName string
+		excluded  bool
+	}{
+		{"net/url.Parse", "my.go.net.url/Parse", false},
+		{"net/url.User", "my.go.net.url/User", true}, // Returns a *Userinfo
+		{"net/url.UserPassword", "my.go.net.url/UserPassword", true},
+	} {
+		f, ok := functions[tc.name]
+		if !ok {
+			t.Errorf("no function %s", tc.name)
+			continue
+		}
+		if f.JokerName != tc.jokerName || f.Excluded != tc.excluded {
+			t.Errorf("%s is %s (excluded: %v), not %s (excluded: %v)", tc.name, f.JokerName, f.Excluded, tc.jokerName, tc.excluded)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package gen
+
+import (
+	"fmt"
+	. "go/ast"
+)
+
+// A TypeConverter maps values of a Go type to and from Joker objects,
+// as params of and results from generated functions. Code it returns
+// may refer to Joker's core package unqualified (e.g. MakeString), to
+// the support package as "gostd.", and to any packages it imports
+// (see TypeConverterImports) as "_<base name>." (e.g. "_uuid.").
+type TypeConverter interface {
+	JokerType() string          // Type (hint) of the Joker objects (e.g. "String"), as documented
+	GoType() string             // The Go type, as documented (e.g. "uuid.UUID")
+	GoToJoker(in string) string // Go expression converting in, of the Go type, to a Joker object
+	JokerToGo(in string) string // Go expression converting in, an Object, to the Go type, or "" if params of the type aren't supported
+}
+
+// A TypeConverter may also name packages its code refers to.
+type TypeConverterImports interface {
+	Imports() []string // E.g. "github.com/google/uuid"
+}
+
+// Converters for Go's scalar types, whose params generate-std.joke
+// (rather than a *_native.go wrapper) converts when they have a Joker
+// type hint. They're keyed, like those in Options.Converters, by the
+// Go type's name: unqualified for predeclared types, else qualified by
+// package path for types defined by the package being wrapped
+// ("net/url.URL"), and by package name for others ("uuid.UUID").
+type builtinConverter struct {
+	name    string // Go type
+	jok     string // Joker type of results
+	gol     string // Go type of results, as documented
+	hint    string // Joker type hint for params (those generate-std.joke supports), if any
+	goParam bool   // Whether params may be declared as this type in *_native.go
+	post    string // Format of Go code making a Joker object, if supported as a result
+	of      string // Support routine converting a Joker object to it, if any
+}
+
+func (c *builtinConverter) JokerType() string {
+	return c.jok
+}
+
+func (c *builtinConverter) GoType() string {
+	return c.gol
+}
+
+func (c *builtinConverter) GoToJoker(in string) string {
+	if c.post == "" {
+		return ""
+	}
+	return fmt.Sprintf(c.post, in)
+}
+
+func (c *builtinConverter) JokerToGo(in string) string {
+	if c.of == "" {
+		return ""
+	}
+	return c.name + "(" + runtimePrefix + c.of + "(" + in + "))"
+}
+
+var builtinConverters = map[string]*builtinConverter{}
+
+func init() {
+	for _, c := range []*builtinConverter{
+		{"string", "String", "string", "String", true, "MakeString(%s)", "StringOf"},
+		{"bool", "Bool", "bool", "Bool", true, "MakeBool(%s)", "BoolOf"},
+		{"int", "Int", "int", "Int", true, "MakeInt(int(%s))", "IntOf"},
+		{"byte", "Int", "int", "Byte", true, "MakeInt(int(%s))", "IntOf"},
+		{"int16", "Int", "int", "", true, "MakeInt(int(%s))", "IntOf"},
+		{"int32", "Int", "int", "", true, "MakeInt(int(%s))", "IntOf"},
+		{"int64", "Int", "int", "", true, "MakeInt(int(%s))", "IntOf"},
+		{"uint", "Int", "int", "", true, "MakeInt(int(%s))", "IntOf"},
+		{"uint16", "Int", "int", "", true, "MakeInt(int(%s))", "IntOf"},
+		{"uint32", "Int", "int", "", true, "MakeInt(int(%s))", "IntOf"},
+		{"int8", "Int", "int", "", false, "", "IntOf"}, // TODO: Does Joker always have 64-bit signed ints?
+		{"uint8", "Int", "int", "", false, "", "IntOf"},
+		{"uint64", "Int", "int", "", false, "", "IntOf"},
+	} {
+		builtinConverters[c.name] = c
+	}
+}
+
+// Returns the name by which converters for the type e would be registered.
+func converterKey(pkg string, e Expr) string {
+	switch v := e.(type) {
+	case *Ident:
+		if _, ok := builtinConverters[v.Name]; ok || v.Name == "error" {
+			return v.Name
+		}
+		return pkg + "." + v.Name
+	case *SelectorExpr:
+		return qualifiedTypeName(e)
+	}
+	return ""
+}
+
+// Returns the converter registered (via Options.Converters) for the
+// type e, if any.
+func (g *Generator) customConverter(pkg string, e Expr) TypeConverter {
+	if k := converterKey(pkg, e); k != "" {
+		return g.converters[k]
+	}
+	return nil
+}
+
+// Returns customConverter(pkg, e), noting (if it's non-nil) that the
+// code being generated uses it, and so the packages it imports.
+func (g *Generator) useConverter(pkg string, e Expr) TypeConverter {
+	c := g.customConverter(pkg, e)
+	if c != nil {
+		g.convertersUsed[converterKey(pkg, e)] = true
+		if ci, ok := c.(TypeConverterImports); ok {
+			g.importsUsed = append(g.importsUsed, ci.Imports()...)
+		}
+	}
+	return c
+}
+
+// Returns the built-in converter for the type e, if any.
+func (g *Generator) builtinConverter(pkg string, e Expr) *builtinConverter {
+	if g.customConverter(pkg, e) != nil {
+		return nil // Overridden
+	}
+	if v, ok := e.(*Ident); ok {
+		return builtinConverters[v.Name]
+	}
+	return nil
+}
+
+// Whether params of type e are converted (in *_native.go) by a custom converter.
+func (g *Generator) customParam(pkg string, e Expr) bool {
+	c := g.customConverter(pkg, e)
+	return c != nil && c.JokerToGo("") != ""
+}
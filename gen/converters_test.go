@@ -0,0 +1,104 @@
+package gen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Converts time.Duration to and from Joker Ints of milliseconds.
+type durationConverter struct{}
+
+func (durationConverter) JokerType() string { return "Int" }
+
+func (durationConverter) GoType() string { return "time.Duration" }
+
+func (durationConverter) GoToJoker(in string) string {
+	return "MakeInt(int((" + in + ") / _time.Millisecond))"
+}
+
+func (durationConverter) JokerToGo(in string) string {
+	return "_time.Duration(" + runtimePrefix + "IntOf(" + in + ")) * _time.Millisecond"
+}
+
+func (durationConverter) Imports() []string { return []string{"time"} }
+
+// Packages with (only) support code, and (only) a function, that use the converter.
+var converterSources = map[string]string{
+	"clock": `package clock
+
+import "time"
+
+type Alarm struct {
+	After time.Duration
+}
+
+// Function whose result is converted dynamically, so the support code converts Alarms.
+func Any() interface{} {
+	return Alarm{}
+}
+`,
+	"timer": `package timer
+
+import "time"
+
+// Function whose param and result are converted.
+func Double(d time.Duration) time.Duration {
+	return 2 * d
+}
+`,
+}
+
+func TestConverterImports(t *testing.T) {
+	goDir, err := ioutil.TempDir("", "gostd2joker-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(goDir)
+	for pkg, code := range converterSources {
+		pkgDir := filepath.Join(goDir, "src", pkg)
+		if err := os.MkdirAll(pkgDir, 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(pkgDir, pkg+".go"), []byte(code), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(goDir, "src", "go"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := New(Options{
+		GoDir:       goDir,
+		JokerDir:    filepath.Join("..", "tests", "joker.orig"), // Only read
+		NoTimestamp: true,
+		Converters:  map[string]TypeConverter{"time.Duration": durationConverter{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	natives := map[string]string{}
+	for _, f := range res.Files {
+		natives[filepath.Base(f.Path)] = f.Contents
+	}
+	for f, wants := range map[string][]string{
+		"clock_native.go": {"RegisterConverter(", "MakeInt(int((_v.After) / _time.Millisecond))"},
+		"timer_native.go": {"func double(d Object) Object {", "_time.Duration(gostd.IntOf(d)) * _time.Millisecond"},
+	} {
+		native := natives[f]
+		for _, want := range wants {
+			if !strings.Contains(native, want) {
+				t.Errorf("%s lacks %q:\n%s", f, want, native)
+			}
+		}
+		if n := strings.Count(native, "\t_time \"time\"\n"); n != 1 {
+			t.Errorf("%s imports time %d times:\n%s", f, n, native)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package gen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/* A reader for the subset of EDN used by configuration files: maps,
+/* vectors, lists, sets, strings, integers, true, false, nil, keywords
+/* and symbols (but not characters, floats nor tagged literals), plus
+/* ; comments and #_ discards. Maps are read as ednMap's (keeping
+/* their order, and the line of each entry, for diagnostics); vectors,
+/* lists and sets as []interface{}. */
+
+type ednKeyword string // Without the leading ':'
+type ednSymbol string
+
+type ednMap []ednEntry
+
+type ednEntry struct {
+	key, value interface{}
+	line       int
+}
+
+type ednReader struct {
+	file string
+	text string
+	pos  int
+	line int
+}
+
+// Reads the sole form in text.
+func readEDN(file, text string) (interface{}, error) {
+	r := &ednReader{file: file, text: text, line: 1}
+	v, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.skip(); err != nil {
+		return nil, err
+	}
+	if r.pos < len(r.text) {
+		return nil, r.errorf("unexpected %q after form", r.text[r.pos])
+	}
+	return v, nil
+}
+
+func (r *ednReader) errorf(format string, args ...interface{}) error {
+	return r.errorAt(r.line, format, args...)
+}
+
+func (r *ednReader) errorAt(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", r.file, line, fmt.Sprintf(format, args...))
+}
+
+// Skips whitespace (including commas), comments, and discarded (#_) forms.
+func (r *ednReader) skip() error {
+	for r.pos < len(r.text) {
+		switch c := r.text[r.pos]; {
+		case c == '\n':
+			r.line++
+			r.pos++
+		case c == ',' || c == ' ' || c == '\t' || c == '\r':
+			r.pos++
+		case c == ';':
+			for r.pos < len(r.text) && r.text[r.pos] != '\n' {
+				r.pos++
+			}
+		case c == '#' && r.pos+1 < len(r.text) && r.text[r.pos+1] == '_':
+			r.pos += 2
+			if _, err := r.read(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *ednReader) read() (interface{}, error) {
+	if err := r.skip(); err != nil {
+		return nil, err
+	}
+	if r.pos >= len(r.text) {
+		return nil, r.errorf("unexpected end of input")
+	}
+	switch c := r.text[r.pos]; c {
+	case '{':
+		r.pos++
+		return r.readMap()
+	case '[':
+		r.pos++
+		return r.readSeq(']')
+	case '(':
+		r.pos++
+		return r.readSeq(')')
+	case '"':
+		return r.readString()
+	case '#':
+		if r.pos+1 < len(r.text) {
+			switch r.text[r.pos+1] {
+			case '{':
+				r.pos += 2
+				return r.readSeq('}')
+			}
+		}
+		return nil, r.errorf("unsupported dispatch after '#'")
+	case ']', ')', '}':
+		return nil, r.errorf("unmatched %q", c)
+	}
+	return r.readAtom()
+}
+
+func (r *ednReader) readMap() (interface{}, error) {
+	m := ednMap{}
+	lines := map[string]int{} // Keys (as written) to the lines of their entries
+	for {
+		if err := r.skip(); err != nil {
+			return nil, err
+		}
+		if r.pos < len(r.text) && r.text[r.pos] == '}' {
+			r.pos++
+			return m, nil
+		}
+		line := r.line
+		k, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.skip(); err != nil {
+			return nil, err
+		}
+		if r.pos < len(r.text) && r.text[r.pos] == '}' {
+			return nil, r.errorf("map literal has no value for key %s", ednString(k))
+		}
+		v, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		ks := ednString(k)
+		if first, found := lines[ks]; found {
+			return nil, r.errorAt(line, "duplicate key %s in map (first at line %d)", ks, first)
+		}
+		lines[ks] = line
+		m = append(m, ednEntry{k, v, line})
+	}
+}
+
+func (r *ednReader) readSeq(end byte) (interface{}, error) {
+	s := []interface{}{}
+	for {
+		if err := r.skip(); err != nil {
+			return nil, err
+		}
+		if r.pos < len(r.text) && r.text[r.pos] == end {
+			r.pos++
+			return s, nil
+		}
+		v, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+}
+
+// EDN's (and Clojure's) string escapes, other than \u.
+var ednEscapes = map[byte]byte{'t': '\t', 'r': '\r', 'n': '\n', 'b': '\b', 'f': '\f', '\\': '\\', '"': '"'}
+
+func (r *ednReader) readString() (interface{}, error) {
+	var b strings.Builder
+	r.pos++
+	for r.pos < len(r.text) {
+		c := r.text[r.pos]
+		r.pos++
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if r.pos >= len(r.text) {
+				return nil, r.errorf("unterminated string")
+			}
+			e := r.text[r.pos]
+			r.pos++
+			if e != 'u' {
+				c, ok := ednEscapes[e]
+				if !ok {
+					return nil, r.errorf("invalid escape \\%c", e)
+				}
+				b.WriteByte(c)
+			} else if r.pos+4 <= len(r.text) {
+				u, err := strconv.ParseUint(r.text[r.pos:r.pos+4], 16, 16)
+				if err != nil {
+					return nil, r.errorf("invalid escape \\u%s", r.text[r.pos:r.pos+4])
+				}
+				b.WriteRune(rune(u))
+				r.pos += 4
+			} else {
+				return nil, r.errorf("invalid escape \\u%s", r.text[r.pos:])
+			}
+		default:
+			if c == '\n' {
+				r.line++
+			}
+			b.WriteByte(c)
+		}
+	}
+	return nil, r.errorf("unterminated string")
+}
+
+func isEDNDelimiter(c byte) bool {
+	return unicode.IsSpace(rune(c)) || strings.IndexByte(`,;"()[]{}`, c) != -1
+}
+
+func (r *ednReader) readAtom() (interface{}, error) {
+	start := r.pos
+	for r.pos < len(r.text) && !isEDNDelimiter(r.text[r.pos]) {
+		r.pos++
+	}
+	tok := r.text[start:r.pos]
+	switch {
+	case tok == "":
+		return nil, r.errorf("unexpected %q", r.text[r.pos])
+	case tok == "nil":
+		return nil, nil
+	case tok == "true":
+		return true, nil
+	case tok == "false":
+		return false, nil
+	case strings.HasPrefix(tok, ":"):
+		if len(tok) == 1 {
+			return nil, r.errorf("invalid keyword")
+		}
+		return ednKeyword(tok[1:]), nil
+	}
+	if i, err := strconv.Atoi(tok); err == nil {
+		return i, nil
+	}
+	if c := tok[0]; c >= '0' && c <= '9' || c == '\\' {
+		return nil, r.errorf("unsupported literal %s", tok)
+	}
+	return ednSymbol(tok), nil
+}
+
+// Returns v as it would be written in EDN (for diagnostics).
+func ednString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(v)
+	case ednKeyword:
+		return ":" + string(v)
+	case ednSymbol:
+		return string(v)
+	case ednMap:
+		s := []string{}
+		for _, e := range v {
+			s = append(s, ednString(e.key)+" "+ednString(e.value))
+		}
+		return "{" + strings.Join(s, ", ") + "}"
+	case []interface{}:
+		s := []string{}
+		for _, e := range v {
+			s = append(s, ednString(e))
+		}
+		return "[" + strings.Join(s, " ") + "]"
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,2703 @@
+package gen
+
+import (
+	"fmt"
+	. "go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const VERSION = "0.1"
+
+/* Want to support e.g.:
+
+     net/dial.go:DialTimeout(network, address string, timeout time.Duration) => _ Conn, _ error
+
+   I.e. a function defined in one package refers to a type defined in
+   another (a different directory, even).
+
+   Sample routines include (from 'net' package):
+     - lookupMX
+     - queryEscape
+   E.g.:
+     ./gostd2joker --dir $PWD/tests 2>&1 | grep -C20 lookupMX
+
+*/
+
+func (g *Generator) whereAt(p token.Pos) string {
+	return fmt.Sprintf("%s", g.fset.Position(p).String())
+}
+
+func unix(p string) string {
+	return filepath.ToSlash(p)
+}
+
+func docString(doc *CommentGroup, extra, jok, gol string) string {
+	var d string
+	if doc != nil {
+		d = doc.Text()
+	}
+	if extra != "" {
+		if d != "" {
+			d = strings.Trim(d, " \t\n") + "\n\n"
+		}
+		d += extra
+	}
+	if gol != "" {
+		if d != "" {
+			d = strings.Trim(d, " \t\n") + "\n\n"
+		}
+		d += "Go return type: " + gol
+	}
+	if jok != "" {
+		if d != "" {
+			d = strings.Trim(d, " \t\n") + "\n\n"
+		}
+		d += "Joker return type: " + jok
+	}
+	return d
+}
+
+type funcInfo struct {
+	fd         *FuncDecl
+	pkg        string // base package name
+	pkgDirUnix string // relative (Unix-style) path to package
+	filename   string // relative (Unix-style) filename within package
+	file       *File  // the file declaring it
+}
+
+/* Go apparently doesn't support/allow 'interface{}' as the value (or
+/* key) of a map such that any arbitrary type can be substituted at
+/* run time, so there are several of these nearly-identical functions
+/* sprinkled through this code. Still get some reuse out of some of
+/* them, and it's still easier to maintain these copies than if the
+/* body of these were to be included at each call point.... */
+func sortedFuncInfoMap(m map[string]*funcInfo, f func(k string, v *funcInfo)) {
+	var keys []string
+	for k, _ := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f(k, m[k])
+	}
+}
+
+// Returns whether any public functions were actually processed.
+func (g *Generator) processFuncDecl(pkg, pkgDirUnix, filename string, f *File, fn *FuncDecl) bool {
+	if g.dump {
+		Fprint(g.log, g.fset, fn, NotNilFilter)
+	}
+	fname := pkgDirUnix + "." + fn.Name.Name
+	if v, ok := g.qualifiedFunctions[fname]; ok {
+		g.diagnose(Note, filename, fmt.Sprintf("Already seen function %s in %s, yet again in %s",
+			fname, v.filename, filename))
+	}
+	g.qualifiedFunctions[fname] = &funcInfo{fn, pkg, pkgDirUnix, filename, f}
+	return true
+}
+
+type typeInfo struct {
+	td       *TypeSpec
+	file     string // Relative (Unix-style) path to defining file
+	building bool
+}
+
+func sortedTypeInfoMap(m map[string]*typeInfo, f func(k string, v *typeInfo)) {
+	var keys []string
+	for k, _ := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f(k, m[k])
+	}
+}
+
+func (g *Generator) processTypeSpec(pkg string, filename string, f *File, ts *TypeSpec) error {
+	if g.dump {
+		Fprint(g.log, g.fset, ts, NotNilFilter)
+	}
+	typename := pkg + "." + ts.Name.Name
+	if c, ok := g.types[typename]; ok {
+		if c.file == filename {
+			return fmt.Errorf("type %s defined twice in file %s", typename, filename)
+		}
+	}
+	g.types[typename] = &typeInfo{ts, filename, false}
+	return nil
+}
+
+func (g *Generator) processTypeSpecs(pkg string, filename string, f *File, tss []Spec) error {
+	for _, spec := range tss {
+		ts := spec.(*TypeSpec)
+		if isPrivate(ts.Name.Name) {
+			continue // Skipping non-exported functions
+		}
+		if err := g.processTypeSpec(pkg, filename, f, ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns whether any public functions were actually processed.
+func (g *Generator) processDecls(pkg, pkgDirUnix, filename string, f *File) (found bool, err error) {
+	for _, s := range f.Decls {
+		switch v := s.(type) {
+		case *FuncDecl:
+			rcv := v.Recv // *FieldList of methods or nil (functions)
+			if rcv != nil {
+				g.methods += 1
+				g.processErrorMethod(pkgDirUnix, v)
+				if t := receiverTypeName(rcv); t != "" && !isPrivate(t) && !isPrivate(v.Name.Name) {
+					g.addDeclaration(Method, pkgDirUnix, filename, t+"."+v.Name.Name, pkgDirUnix+"."+t, goSignature(v))
+				}
+				continue // Skipping these for now
+			}
+			if isPrivate(v.Name.Name) {
+				continue // Skipping non-exported functions
+			}
+			if g.processFuncDecl(pkg, pkgDirUnix, filename, f, v) {
+				found = true
+			}
+		case *GenDecl:
+			switch v.Tok {
+			case token.TYPE:
+				if err = g.processTypeSpecs(pkgDirUnix, filename, f, v.Specs); err != nil {
+					return
+				}
+			case token.CONST, token.VAR:
+				kind := Constant
+				if v.Tok == token.VAR {
+					kind = Variable
+				}
+				var typ Expr
+				var values []Expr
+				for _, spec := range v.Specs {
+					vs := spec.(*ValueSpec)
+					if v.Tok == token.VAR || vs.Type != nil || len(vs.Values) != 0 {
+						typ, values = vs.Type, vs.Values
+					} // Else a constant repeating the preceding spec's type and values (e.g. iota)
+					for i, n := range vs.Names {
+						if !isPrivate(n.Name) {
+							g.addDeclaration(kind, pkgDirUnix, filename, n.Name, "", valueSignature(v.Tok, n.Name, typ, values, i))
+						}
+					}
+				}
+			}
+		default:
+			err = fmt.Errorf("unrecognized Decl type %T at: %s", v, g.whereAt(v.Pos()))
+			return
+		}
+	}
+	return
+}
+
+// Records an exported method, constant, or variable (none of which are wrapped).
+func (g *Generator) addDeclaration(kind DeclKind, pkgDirUnix, filename, name, receiver, signature string) {
+	g.decls = append(g.decls, &Declaration{Name: pkgDirUnix + "." + name, Kind: kind, Package: pkgDirUnix, File: filename,
+		Receiver: receiver, Signature: signature})
+}
+
+// Returns the name of the type of the receiver (e.g. "URL", given "u *URL"), or "" if it isn't named.
+func receiverTypeName(rcv *FieldList) string {
+	if len(rcv.List) != 1 {
+		return ""
+	}
+	t := rcv.List[0].Type
+	if v, ok := t.(*StarExpr); ok {
+		t = v.X
+	}
+	switch v := t.(type) {
+	case *IndexExpr: // A generic type
+		t = v.X
+	case *IndexListExpr:
+		t = v.X
+	}
+	if v, ok := t.(*Ident); ok {
+		return v.Name
+	}
+	return ""
+}
+
+// Describes the kind of the type defined by ts, e.g. "struct".
+func typeKind(ts *TypeSpec) string {
+	if ts.Assign.IsValid() {
+		return "alias"
+	}
+	switch v := ts.Type.(type) {
+	case *StructType:
+		return "struct"
+	case *InterfaceType:
+		return "interface"
+	case *FuncType:
+		return "func"
+	case *MapType:
+		return "map"
+	case *ChanType:
+		return "chan"
+	case *ArrayType:
+		if v.Len == nil {
+			return "slice"
+		}
+		return "array"
+	case *StarExpr:
+		return "pointer"
+	}
+	return "named" // Another named (or a basic) type, e.g. "type Header textproto.MIMEHeader" or "type Duration int64"
+}
+
+// Returns the Go signature of fd, less the names of its receiver,
+// params, and results (so renaming them doesn't change it), e.g.
+// "func Parse(string) (*URL, error)".
+func goSignature(fd *FuncDecl) string {
+	s := "func "
+	if fd.Recv != nil {
+		s += "(" + fieldListString(typesOnly(fd.Recv).List, ", ") + ") "
+	}
+	s += fd.Name.Name
+	if fd.Type.TypeParams != nil {
+		s += "[" + fieldListString(fd.Type.TypeParams.List, ", ") + "]"
+	}
+	ft := &FuncType{Params: typesOnly(fd.Type.Params), Results: typesOnly(fd.Type.Results)}
+	return s + strings.TrimPrefix(types.ExprString(ft), "func")
+}
+
+// Returns the types of the fields, unnamed, one per (named) field,
+// e.g. "int, int, string" given "a, b int, c string".
+func typesOnly(fl *FieldList) *FieldList {
+	if fl == nil {
+		return nil
+	}
+	fields := []*Field{}
+	for _, f := range fl.List {
+		for i := 0; i == 0 || i < len(f.Names); i++ {
+			fields = append(fields, &Field{Type: f.Type})
+		}
+	}
+	return &FieldList{List: fields}
+}
+
+// Returns the Go signature of the name'th constant or variable
+// declared by tok, of the type (if any) and with the values (if any),
+// e.g. "const ModeDir FileMode = 1 << (32 - 1 - iota)". A variable's
+// value is included only if it has no declared type.
+func valueSignature(tok token.Token, name string, typ Expr, values []Expr, index int) string {
+	s := tok.String() + " " + name
+	if typ != nil {
+		s += " " + types.ExprString(typ)
+		if tok == token.VAR {
+			return s
+		}
+	}
+	switch {
+	case len(values) == 0:
+		return s
+	case index < len(values) && len(values) > 1:
+		return s + " = " + types.ExprString(values[index])
+	}
+	vs := []string{}
+	for _, v := range values {
+		vs = append(vs, types.ExprString(v))
+	}
+	return s + " = " + strings.Join(vs, ", ") // E.g. "var a, b = f()"
+}
+
+// Returns the Go definition of the type defined by ts, e.g. "type
+// Values map[string][]string", omitting the unexported fields of a
+// struct (as they don't affect its API).
+func typeDefinition(ts *TypeSpec) string {
+	s := "type " + ts.Name.Name
+	if ts.TypeParams != nil {
+		s += "[" + fieldListString(ts.TypeParams.List, ", ") + "]"
+	}
+	if ts.Assign.IsValid() {
+		s += " ="
+	}
+	st, ok := ts.Type.(*StructType)
+	if !ok {
+		return s + " " + types.ExprString(ts.Type)
+	}
+	fields := []*Field{}
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 { // Embedded, e.g. "*bufio.Reader"
+			t := strings.TrimPrefix(types.ExprString(f.Type), "*")
+			if i := strings.Index(t, "["); i >= 0 {
+				t = t[:i]
+			}
+			if !isPrivate(t[strings.LastIndex(t, ".")+1:]) {
+				fields = append(fields, f)
+			}
+			continue
+		}
+		names := []*Ident{}
+		for _, n := range f.Names {
+			if !isPrivate(n.Name) {
+				names = append(names, n)
+			}
+		}
+		if len(names) != 0 {
+			fields = append(fields, &Field{Names: names, Type: f.Type})
+		}
+	}
+	return s + " struct{" + fieldListString(fields, "; ") + "}"
+}
+
+// Returns the fields separated by sep, e.g. "a, b int; c string".
+func fieldListString(fields []*Field, sep string) string {
+	fs := []string{}
+	for _, f := range fields {
+		names := []string{}
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		s := types.ExprString(f.Type)
+		if len(names) != 0 {
+			s = strings.Join(names, ", ") + " " + s
+		}
+		fs = append(fs, s)
+	}
+	return strings.Join(fs, sep)
+}
+
+func sortedErrorTypes(m map[string]bool, f func(k string, ptr bool)) {
+	var keys []string
+	for k, _ := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f(k, m[k])
+	}
+}
+
+// Records the receiver's type if fn is "Error() string".
+func (g *Generator) processErrorMethod(pkgDirUnix string, fn *FuncDecl) {
+	if fn.Name.Name != "Error" || len(fn.Type.Params.List) != 0 {
+		return
+	}
+	fl := fn.Type.Results
+	if fl == nil || countFields(*fl) != 1 {
+		return
+	}
+	if v, ok := fl.List[0].Type.(*Ident); !ok || v.Name != "string" {
+		return
+	}
+	ptr := false
+	t := fn.Recv.List[0].Type
+	if v, ok := t.(*StarExpr); ok {
+		ptr = true
+		t = v.X
+	}
+	if v, ok := t.(*Ident); ok && !isPrivate(v.Name) {
+		g.errorTypes[pkgDirUnix+"."+v.Name] = ptr
+	}
+}
+
+var exists = struct{}{}
+
+/* Maps relative package (unix-style) names to their imports, non-emptiness, etc. */
+type packageImports map[string]struct{}
+type packageInfo struct {
+	importsNative  packageImports
+	importsAutoGen packageImports
+	nonEmpty       bool // Whether any non-comment code has been generated
+	hasGoFiles     bool // Whether any .go files (would) have been generated
+	importsRuntime bool // Whether generated Go code refers to the support package
+}
+
+/* Sort the packages -- currently appears to not actually be
+/* necessary, probably because of how walkDirs() works. */
+func sortedPackagesInfo(m map[string]*packageInfo, f func(k string, i *packageInfo)) {
+	var keys []string
+	for k, _ := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f(k, m[k])
+	}
+}
+
+func sortedPackageImports(pi packageImports, f func(k string)) {
+	var keys []string
+	for k, _ := range pi {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f(k)
+	}
+}
+
+func (g *Generator) processPackage(pkgDir, pkgDirUnix, pkg string, p *Package) error {
+	if g.verbose {
+		g.logf("Processing package=%s in %s:\n", pkg, pkgDirUnix)
+	}
+	found := false
+	for filename, f := range p.Files {
+		fileFound, err := g.processDecls(pkg, pkgDirUnix, filepath.ToSlash(filename), f)
+		if err != nil {
+			return err
+		}
+		if fileFound {
+			found = true
+		}
+	}
+	if found {
+		if _, ok := g.packagesInfo[pkgDirUnix]; !ok {
+			g.packagesInfo[pkgDirUnix] = &packageInfo{packageImports{}, packageImports{}, false, false, false}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) processDir(d string, path string, mode parser.Mode) error {
+	pkgDir := strings.TrimPrefix(path, d+string(filepath.Separator))
+	pkgDirUnix := filepath.ToSlash(pkgDir)
+	if g.verbose {
+		g.logf("Processing %s:\n", pkgDirUnix)
+	}
+
+	pkgs, err := parser.ParseDir(g.fset, path,
+		// Walk only *.go files that meet default (target) build constraints, e.g. per "// build ..."
+		func(info os.FileInfo) bool {
+			if strings.HasSuffix(info.Name(), "_test.go") {
+				if g.verbose {
+					g.logf("Ignoring test code in %s\n", info.Name())
+				}
+				return false
+			}
+			b, e := build.Default.MatchFile(path, info.Name())
+			if g.verbose {
+				g.logf("Matchfile(%s) => %v %v\n",
+					filepath.ToSlash(filepath.Join(path, info.Name())),
+					b, e)
+			}
+			return b && e == nil
+		},
+		mode)
+	if err != nil {
+		return err
+	}
+
+	basename := filepath.Base(path)
+	for k, v := range pkgs {
+		if k != basename && k != basename+"_test" {
+			if g.verbose {
+				g.logf("NOTICE: Package %s is defined in %s -- ignored\n", k, path)
+			}
+		} else {
+			if g.verbose {
+				g.logf("Package %s:\n", k)
+			}
+			if err := g.processPackage(pkgDir, pkgDirUnix, k, v); err != nil { // processPackage(strings.Replace(path, d + "/", "", 1) + "/" + k, v)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var excludeDirs = map[string]bool{
+	"builtin":  true,
+	"cmd":      true,
+	"internal": true, // look into this later?
+	"testdata": true,
+	"vendor":   true,
+}
+
+func (g *Generator) walkDirs(d string, mode parser.Mode) error {
+	target, err := filepath.EvalSymlinks(d)
+	if err != nil {
+		return err
+	}
+	err = filepath.Walk(target,
+		func(path string, info os.FileInfo, err error) error {
+			rel := strings.Replace(path, target, d, 1)
+			if err != nil {
+				g.diagnose(Warning, "", fmt.Sprintf("Skipping %s due to: %v", filepath.ToSlash(rel), err))
+				return err
+			}
+			if rel == d {
+				return nil // skip (implicit) "."
+			}
+			if info.IsDir() {
+				g.dirNamesSeen[filepath.Base(rel)] = true
+			}
+			if g.excludeDirs[filepath.Base(rel)] {
+				if g.verbose {
+					g.logf("Excluding %s\n",
+						filepath.ToSlash(rel))
+				}
+				return filepath.SkipDir
+			}
+			if info.IsDir() {
+				if g.verbose {
+					g.logf("Walking from %s to %s\n",
+						filepath.ToSlash(d), filepath.ToSlash(rel))
+				}
+				pkgDirUnix := filepath.ToSlash(strings.TrimPrefix(rel, d+string(filepath.Separator)))
+				g.dirsSeen[pkgDirUnix] = true
+				if !g.packageWanted(pkgDirUnix) {
+					if g.verbose {
+						g.logf("Excluding package %s\n", pkgDirUnix)
+					}
+					return nil // but still walk its subdirectories
+				}
+				return g.processDir(d, rel, mode)
+			}
+			return nil // not a directory
+		})
+
+	return err
+}
+
+// Whether e is interface{} (or any), values of which are converted dynamically.
+func isEmptyInterface(e Expr) bool {
+	switch v := e.(type) {
+	case *InterfaceType:
+		return v.Methods == nil || len(v.Methods.List) == 0
+	case *Ident:
+		return v.Name == "any"
+	}
+	return false
+}
+
+// Whether e is ...interface{} (or ...any), passed from Joker as a single seqable.
+func isDynamicVariadic(e Expr) bool {
+	v, ok := e.(*Ellipsis)
+	return ok && isEmptyInterface(v.Elt)
+}
+
+// Returns e as a qualified Go type name (e.g. "io.Reader" or
+// "*bufio.Reader"), or "" if it isn't one.
+func qualifiedTypeName(e Expr) string {
+	switch v := e.(type) {
+	case *StarExpr:
+		if s := qualifiedTypeName(v.X); s != "" {
+			return "*" + s
+		}
+	case *SelectorExpr:
+		if x, ok := v.X.(*Ident); ok {
+			return x.Name + "." + v.Sel.Name
+		}
+	}
+	return ""
+}
+
+/* Map Go stream types, as parameters, to the support-package
+/* functions that convert Joker objects (strings, files, *in*,
+/* *out*, and so on) to them. */
+var streamParams = map[string]string{
+	"io.Reader":     "ToReader",
+	"io.Writer":     "ToWriter",
+	"*bufio.Reader": "ToBufioReader",
+	"*bufio.Writer": "ToBufioWriter",
+}
+
+/* Go stream types that, as results, are returned to Joker as
+/* opaque objects readable (or writable) via go.gostd functions. */
+var streamResults = map[string]bool{
+	"io.Reader":      true,
+	"io.ReadCloser":  true,
+	"io.Writer":      true,
+	"io.WriteCloser": true,
+	"*bufio.Reader":  true,
+	"*bufio.Writer":  true,
+}
+
+// Returns the support-package function that converts a Joker
+// argument to a parameter of type e, or "" if none is needed.
+func paramConversion(e Expr) string {
+	if isEmptyInterface(e) {
+		return "ToGo"
+	}
+	if isDynamicVariadic(e) {
+		return "ToGoArgs"
+	}
+	if isContext(e) {
+		return "ToContext"
+	}
+	if v, ok := e.(*ArrayType); ok && v.Len == nil && types.ExprString(v.Elt) == "byte" {
+		return "BytesOf"
+	}
+	return streamParams[qualifiedTypeName(e)]
+}
+
+func isContext(e Expr) bool {
+	return qualifiedTypeName(e) == "context.Context"
+}
+
+// Returns the name of fl's first parameter if it is a context.Context,
+// which Joker callers may omit (getting a default context), else "".
+func leadingContext(fl *FieldList) string {
+	if len(fl.List) == 0 || len(fl.List[0].Names) == 0 || !isContext(fl.List[0].Type) {
+		return ""
+	}
+	return fl.List[0].Names[0].Name
+}
+
+// Generates code that converts each context.Context param (passed
+// from Joker as nil, a timeout, or a context handle) to _param.
+func genContextParams(indent string, fl *FieldList) (code string) {
+	for _, f := range fl.List {
+		if !isContext(f.Type) {
+			continue
+		}
+		for _, n := range f.Names {
+			p := paramNameAsGo(n.Name)
+			code += indent + "_" + p + ", _" + p + "Done := " + runtimePrefix + "ToContext(" + p + ")\n" +
+				indent + "defer _" + p + "Done()\n"
+		}
+	}
+	return
+}
+
+// Returns the signature of the callback type e (a func type, or a
+// single-method interface, in which case method names that method), or
+// nil if e isn't one. Joker fns may be passed for such params.
+func (g *Generator) callbackSig(pkg string, e Expr) (ft *FuncType, method string) {
+	switch v := e.(type) {
+	case *FuncType:
+		return v, ""
+	case *Ident:
+		ti, found := g.types[pkg+"."+v.Name]
+		if !found || ti.td.TypeParams != nil {
+			return nil, ""
+		}
+		switch t := ti.td.Type.(type) {
+		case *FuncType:
+			return t, ""
+		case *InterfaceType:
+			if t.Methods != nil && len(t.Methods.List) == 1 && len(t.Methods.List[0].Names) == 1 {
+				if ft, ok := t.Methods.List[0].Type.(*FuncType); ok {
+					return ft, t.Methods.List[0].Names[0].Name
+				}
+			}
+		}
+	}
+	return nil, ""
+}
+
+func (g *Generator) isCallback(pkg string, e Expr) bool {
+	ft, _ := g.callbackSig(pkg, e)
+	return ft != nil
+}
+
+func (g *Generator) hasCallbackParams(pkg string, fl *FieldList) bool {
+	for _, f := range fl.List {
+		if g.isCallback(pkg, f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// Generates code that adapts each Joker fn, passed for a callback
+// param, to _param, which Go may call from any goroutine.
+func (g *Generator) genCallbackParams(indent, pkg string, fl *FieldList) (code string) {
+	for _, f := range fl.List {
+		ft, method := g.callbackSig(pkg, f.Type)
+		if ft == nil {
+			continue
+		}
+		for _, n := range f.Names {
+			p := paramNameAsGo(n.Name)
+			if method == "" {
+				sig, body := g.genCallbackFunc(indent+"\t", pkg, "_"+p+"Fn", ft)
+				code += indent + "_" + p + "Fn := " + runtimePrefix + "CallbackOf(" + p + ")\n" +
+					indent + "_" + p + " := func" + sig + " {\n" + body + indent + "}\n"
+			} else {
+				adapter := g.genCallbackAdapter(pkg, f.Type.(*Ident).Name, method, ft)
+				code += indent + "_" + p + " := " + adapter + "{" + runtimePrefix + "CallbackOf(" + p + ")}\n"
+			}
+		}
+	}
+	return
+}
+
+// Generates the signature and body of a Go func, of type ft, that
+// calls the Joker fn (a Callable) with its args converted to Joker
+// objects (or, if they have no such representation, GoObject's), and
+// converts the result back.
+func (g *Generator) genCallbackFunc(indent, pkg, fn string, ft *FuncType) (sig, body string) {
+	params := ""
+	args := ""
+	i := 0
+	for _, f := range ft.Params.List {
+		t := g.goTypeName(pkg, f.Type)
+		if t == "" {
+			t = g.abendAt("751", f.Type.Pos(), g.diagType(pkg, f.Type), "unsupported callback param type")
+		}
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for ; n > 0; n-- {
+			i++
+			a := fmt.Sprintf("_a%d", i)
+			if params != "" {
+				params += ", "
+			}
+			params += a + " " + t
+			goc, out := g.genGoPostData(indent, pkg, a, f.Type, runtimePrefix+"MakeGoObject("+a+")") // Else a live handle to, e.g., an http.ResponseWriter
+			body += goc
+			args += ", " + out
+		}
+	}
+	call := runtimePrefix + "Call(" + fn + args + ")"
+	results := ""
+	switch countResultsOf(ft) {
+	case 0:
+		body += indent + call + "\n"
+	case 1:
+		rt := ft.Results.List[0].Type
+		results = " " + g.goTypeName(pkg, rt)
+		conv := g.jokerToGo(pkg, rt, "_r")
+		if isErrorType(rt) {
+			conv = runtimePrefix + "ErrorOf(_r)"
+		}
+		if conv == "" {
+			conv = g.abendAt("752", rt.Pos(), g.diagType(pkg, rt), "unsupported callback result type")
+		}
+		body += indent + "_r := " + call + "\n" +
+			indent + "return " + conv + "\n"
+	default:
+		body += indent + g.abend("753", ft.Results.Pos(), diagType{}, "multiple callback results not supported") + "\n"
+	}
+	sig = "(" + params + ")" + results
+	return
+}
+
+// Generates code converting in, of type e, to Joker data if isCallbackData(e) and
+// that's supported; else returns the fallback conversion.
+func (g *Generator) genGoPostData(indent, pkg, in string, e Expr, fallback string) (goc, out string) {
+	if g.isCallbackData(pkg, e) {
+		mark := len(g.pending)
+		_, _, goc, out = g.genGoPostExpr(indent, pkg, in, e, "")
+		if out != "" && exprIsUseful(out) && len(g.pending) == mark {
+			return
+		}
+		g.pending = g.pending[:mark]
+	}
+	return "", fallback
+}
+
+// Whether callback args of type e are passed to Joker as data (rather than as GoObject's).
+func (g *Generator) isCallbackData(pkg string, e Expr) bool {
+	if v, ok := e.(*ArrayType); ok && v.Len == nil {
+		e = v.Elt
+	}
+	return g.jokerToGo(pkg, e, "") != "" || isEmptyInterface(e) || isErrorType(e)
+}
+
+func countResultsOf(ft *FuncType) int {
+	if ft.Results == nil {
+		return 0
+	}
+	return countFields(*ft.Results)
+}
+
+// Returns the name of a type implementing the single-method interface
+// iface (defined in pkg) by calling a Joker fn, generating it if need be.
+func (g *Generator) genCallbackAdapter(pkg, iface, method string, ft *FuncType) string {
+	name := funcNameAsGoPrivate(iface) + "Adapter"
+	if _, ok := g.callbackAdapters[pkg][name]; ok {
+		return name
+	}
+	mark := len(g.pending)
+	sig, body := g.genCallbackFunc("\t", pkg, "a.fn", ft)
+	code := `
+type ` + name + ` struct {
+	fn Callable
+}
+
+func (a ` + name + `) ` + method + sig + ` {
+` + body + `}
+`
+	if len(g.pending) != mark {
+		g.pending = g.pending[:mark] // Reported as the interface that can't be implemented, instead
+		return g.abend("754", ft.Pos(), localType(pkg, iface), fmt.Sprintf("cannot implement %s.%s via a Joker fn", pkg, iface))
+	}
+	if _, ok := g.callbackAdapters[pkg]; !ok {
+		g.callbackAdapters[pkg] = codeInfo{}
+	}
+	g.callbackAdapters[pkg][name] = code
+	return name
+}
+
+// Whether any parameters must be converted at runtime (so the function needs a native wrapper).
+func (g *Generator) hasConvertedParams(pkg string, fl *FieldList) bool {
+	for _, f := range fl.List {
+		if g.customParam(pkg, f.Type) || paramConversion(f.Type) != "" || g.isCallback(pkg, f.Type) || g.structParam(pkg, f.Type) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) exprAsClojure(pkg string, e Expr) string {
+	if g.customParam(pkg, e) || paramConversion(e) != "" || g.isCallback(pkg, e) || g.structParam(pkg, e) != "" {
+		return "Object"
+	}
+	switch v := e.(type) {
+	case *Ident:
+		if c := g.builtinConverter(pkg, e); c != nil && c.hint != "" {
+			return c.hint
+		}
+		return g.abendAt("885", e.Pos(), g.diagType(pkg, e), "unrecognized type "+v.Name)
+	default:
+		return g.abendAt("881", e.Pos(), g.diagType(pkg, e), fmt.Sprintf("unrecognized Expr type %T", e))
+	}
+}
+
+func (g *Generator) exprAsGo(pkg string, e Expr) string {
+	if g.customParam(pkg, e) || paramConversion(e) != "" || g.isCallback(pkg, e) || g.structParam(pkg, e) != "" {
+		return "Object"
+	}
+	switch v := e.(type) {
+	case *Ident:
+		if c := g.builtinConverter(pkg, e); (c != nil && c.goParam) || v.Name == "error" {
+			return v.Name
+		}
+		return g.abendAt("884", e.Pos(), g.diagType(pkg, e), "unrecognized type "+v.Name)
+	default:
+		return g.abendAt("882", e.Pos(), g.diagType(pkg, e), fmt.Sprintf("unrecognized Expr type %T", e))
+	}
+}
+
+func paramNameAsClojure(n string) string {
+	return n
+}
+
+func (g *Generator) fieldListAsClojure(pkg string, fl *FieldList) string {
+	if fl == nil {
+		return ""
+	}
+	var s string
+	for _, f := range fl.List {
+		cltype := g.exprAsClojure(pkg, f.Type)
+		for _, p := range f.Names {
+			if s != "" {
+				s += ", "
+			}
+			if cltype != "" {
+				s += "^" + cltype + " "
+			}
+			if p == nil {
+				s += "_"
+			} else {
+				s += "_" + paramNameAsClojure(p.Name)
+			}
+		}
+	}
+	return s
+}
+
+func (g *Generator) fieldListToGo(fl *FieldList) string {
+	s := ""
+	for _, f := range fl.List {
+		for _, p := range f.Names {
+			if s != "" {
+				s += ", "
+			}
+			if p == nil {
+				s += g.abend("922", f.Pos(), diagType{}, "unnamed param")
+			} else {
+				s += "_" + p.Name
+			}
+		}
+	}
+	return s
+}
+
+// Go keywords and predeclared identifiers, which generated (private) names mustn't shadow.
+var goReserved = map[string]bool{
+	"bool": true, "byte": true, "complex": true, "copy": true, "error": true, "float32": true,
+	"float64": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"len": true, "make": true, "new": true, "panic": true, "print": true, "println": true,
+	"real": true, "recover": true, "rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true, "append": true, "cap": true,
+	"close": true, "delete": true, "imag": true, "iota": true, "nil": true, "true": true, "false": true,
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true,
+}
+
+func funcNameAsGoPrivate(f string) string {
+	p := strings.ToLower(f[0:1]) + f[1:]
+	if goReserved[p] {
+		p += "_"
+	}
+	return p
+}
+
+func paramNameAsGo(p string) string {
+	return p
+}
+
+func (g *Generator) paramListAsGo(pkg string, fl *FieldList) string {
+	s := ""
+	for _, f := range fl.List {
+		gotype := g.exprAsGo(pkg, f.Type)
+		for _, p := range f.Names {
+			if s != "" {
+				s += ", "
+			}
+			if p == nil {
+				s += g.abend("712", f.Pos(), diagType{}, "unnamed param")
+			} else {
+				s += paramNameAsGo(p.Name)
+			}
+			if gotype != "" {
+				s += " " + gotype
+			}
+		}
+	}
+	return s
+}
+
+func (g *Generator) argsAsGo(pkg string, p *FieldList, outs map[string]bool) string {
+	s := ""
+	for _, f := range p.List {
+		for _, p := range f.Names {
+			if s != "" {
+				s += ", "
+			}
+			if p == nil {
+				s += g.abend("713", f.Pos(), diagType{}, "unnamed param")
+			} else if outs[p.Name] && isDynamicOut(f.Type) {
+				s += "_" + paramNameAsGo(p.Name) // As allocated by genOutParams
+				if isDynamicVariadic(f.Type) {
+					s += "..."
+				}
+			} else {
+				if outs[p.Name] {
+					s += "&"
+				}
+				if g.customParam(pkg, f.Type) {
+					s += g.useConverter(pkg, f.Type).JokerToGo(paramNameAsGo(p.Name))
+				} else if isContext(f.Type) || g.isCallback(pkg, f.Type) || g.structParam(pkg, f.Type) != "" {
+					s += "_" + paramNameAsGo(p.Name)
+				} else if conv := paramConversion(f.Type); conv != "" {
+					s += runtimePrefix + conv + "(" + paramNameAsGo(p.Name) + ")"
+					if isDynamicVariadic(f.Type) {
+						s += "..."
+					}
+				} else {
+					s += paramNameAsGo(p.Name)
+				}
+			}
+		}
+	}
+	return s
+}
+
+/* The transformation code, below, takes an approach that is new for me.
+
+   Instead of each transformation point having its own transform
+   routine(s), as is customary, I'm trying an approach in which the
+   transform is driven by the input and multiple outputs are
+   generated, where appropriate, for further processing and/or
+   insertion into the ultimate transformation points.
+
+   The primary reason for this is that the input is complicated and
+   (generally) being supported to a greater extent as enhancements are
+   made. I want to maintain coherence among the various transformation
+   insertions, so it's less likely that a change made for one
+   insertion point (to support a new input form, or modify an existing
+   one) won't have corresponding changes made to other forms relying
+   on the same essential input, which could lead to coding errors.
+
+   This approach also should make it easier to see how the different
+   snippets of code, relating to one particular aspect of the input,
+   relate to each other, because the code will be in the same place.
+
+   However, I'm concerned that the resulting code will be too
+   complicated for that to be sufficiently helpful. If I was
+   proficient in a constraint/unification-based transformation
+   language, I'd look at that instead, because it would allow me to
+   express that e.g. "func foo(args) (returns) { ...do things with
+   args...; call foo in some fashion; ...do things with returns... }"
+   not only have specific transformations for each of the variables
+   involved, but that they are also constrained in some fashion
+   (e.g. whatever names are picked for unnamed 'returns' values are
+   the same in both "returns" and "do things with returns"; whatever
+   types are involved in both "args" and "returns" are properly
+   processed in "do things with args" and "do things with returns",
+   respectively; and so on).
+
+   Now that I've refactored the code to achieve this, I'll start
+   adding transformations and see how it goes. Might revert to
+   old-fashioned use of custom transformation code per point (sharing
+   code where appropriate, of course) if it gets too hairy.
+
+*/
+
+func (g *Generator) genSym(pre string) string {
+	var idx int
+	if i, ok := g.genSymIndex[pre]; ok {
+		idx = i + 1
+	} else {
+		idx = 1
+	}
+	g.genSymIndex[pre] = idx
+	return fmt.Sprintf("%s%d", pre, idx)
+}
+
+func (g *Generator) genSymReset() {
+	g.genSymIndex = map[string]int{}
+}
+
+func exprIsUseful(rtn string) bool {
+	return rtn != "NIL"
+}
+
+func (g *Generator) genGoPostNamed(indent, pkg, in string, id *Ident, onlyIf string) (jok, gol, goc, out string) {
+	t := id.Name
+	qt := pkg + "." + t
+	if v, ok := g.types[qt]; ok {
+		if v.building { // Mutually-referring types currently not supported
+			jok = g.abend("947", v.td.Pos(), localType(pkg, t),
+				"recursive type reference involving "+qt) // TODO: handle these, e.g. http Request/Response
+			gol = jok
+			goc = ""
+		} else {
+			v.building = true
+			if u, ok := v.td.Type.(*Ident); ok && (u.Name == "string" || u.Name == "bool") {
+				in = u.Name + "(" + in + ")" // MakeString() etc. don't accept named types
+			}
+			if _, ok := v.td.Type.(*StructType); ok && g.maxDepth > 0 && g.structDepth >= g.maxDepth {
+				v.building = false
+				jok = "GoObject"
+				gol = qt
+				out = runtimePrefix + "MakeGoObject(&" + in + ")" // Too deep to convert, so return a handle
+				return
+			}
+			mark := len(g.pending)
+			jok, gol, goc, out = g.genGoPostExpr(indent, pkg, in, v.td.Type, onlyIf)
+			for i := mark; i < len(g.pending); i++ {
+				if g.pending[i].pos == v.td.Type.Pos() {
+					g.pending[i].Type, g.pending[i].TypePath = path.Base(pkg)+"."+t, qt // Rather than its (unsupported) definition
+				}
+			}
+			if _, ok := v.td.Type.(*StructType); ok && exprIsUseful(out) {
+				if g.roundTrip {
+					out = runtimePrefix + "TagGoValue(" + out + ", \"" + qt + "\", &" + in + ")"
+				} else {
+					out = runtimePrefix + "TagGoType(" + out + ", \"" + qt + "\")" // For go-type and the type predicates
+				}
+			}
+			v.building = false
+		}
+	} else {
+		typ := localType(pkg, t)
+		if types.Universe.Lookup(t) != nil {
+			typ = diagType{t, t} // E.g. float64, lacking a Joker conversion
+		}
+		jok = g.abend("042", id.Pos(), typ, "cannot find typename "+qt)
+	}
+	return
+}
+
+func isPrivate(p string) bool {
+	return !unicode.IsUpper(rune(p[0]))
+}
+
+// func tryThis(s string) struct { a int; b string } {
+//	return struct { a int; b string }{ 5, "hey" }
+// }
+
+// Joker: { :a ^Int, :b ^String }
+// Go: struct { a int; b string }
+func (g *Generator) genGoPostStruct(indent, pkg, in string, fl *FieldList, onlyIf string) (jok, gol, goc, out string) {
+	tmpmap := "_map" + g.genSym("")
+	useful := false
+	g.structDepth++
+	defer func() { g.structDepth-- }()
+	for _, f := range fl.List {
+		for _, p := range f.Names {
+			if isPrivate(p.Name) {
+				continue // Skipping non-exported fields
+			}
+			var joktype, goltype, more_goc string
+			joktype, goltype, more_goc, out =
+				g.genGoPostExpr(indent, pkg, in+"."+p.Name, f.Type, "")
+			if useful || exprIsUseful(out) {
+				useful = true
+			}
+			goc += more_goc
+			goc += indent + tmpmap +
+				".Add(MakeKeyword(\"" + p.Name + "\"), " + out + ")\n"
+			if jok != "" {
+				jok += ", "
+			}
+			if gol != "" {
+				gol += "; "
+			}
+			if p == nil {
+				jok += "_ "
+			} else {
+				jok += ":" + p.Name + " "
+				gol += p.Name + " "
+			}
+			if joktype != "" {
+				jok += "^" + joktype
+			}
+			if goltype != "" {
+				gol += goltype
+			}
+		}
+	}
+	jok = "{" + jok + "}"
+	gol = "struct {" + gol + "}"
+	if useful {
+		goc = wrapStmtOnlyIfs(indent, tmpmap, "ArrayMap", "EmptyArrayMap()", onlyIf, goc, &out)
+	} else {
+		goc = ""
+		out = "NIL"
+	}
+	return
+}
+
+func (g *Generator) genGoPostArray(indent, pkg, in string, el Expr, onlyIf string) (jok, gol, goc, out string) {
+	tmp := g.genSym("")
+	tmpvec := "_vec" + tmp
+	tmpelem := "_elem" + tmp
+
+	var goc_pre string
+	elemIndent := indent + "\t"
+	if g.lazySlices && onlyIf != "" {
+		elemIndent += "\t"
+	}
+	jok, gol, goc_pre, out = g.genGoPostExpr(elemIndent, pkg, tmpelem, el, "")
+	useful := exprIsUseful(out)
+	jok = "(vector-of " + jok + ")"
+	gol = "[]" + gol
+
+	if useful && g.lazySlices {
+		jok = "(seq-of" + strings.TrimPrefix(jok, "(vector-of")
+		lazy := runtimePrefix + "LazySlice(len(" + in + "), func(_i int) Object {\n" +
+			elemIndent + tmpelem + " := " + in + "[_i]\n" +
+			goc_pre +
+			elemIndent + "return " + out + "\n" +
+			elemIndent[1:] + "})"
+		goc = wrapStmtOnlyIfs(indent, tmpvec, "Object", lazy, onlyIf, "", &out)
+	} else if useful {
+		goc = indent + "for _, " + tmpelem + " := range " + in + " {\n"
+		goc += goc_pre
+		goc += indent + "\t" + tmpvec + " = " + tmpvec + ".Conjoin(" + out + ")\n"
+		goc += indent + "}\n"
+		goc = wrapStmtOnlyIfs(indent, tmpvec, "Vector", "EmptyVector", onlyIf, goc, &out)
+	} else {
+		goc = ""
+	}
+	return
+}
+
+// TODO: Maybe return a ref or something Joker (someday) supports? flag.String() is useful only as it returns a ref;
+// whereas net.LookupMX() returns []*MX, and these are not only populated, it's unclear there's any utility in
+// modifying them (it could just as well return []MX AFAICT).
+func (g *Generator) genGoPostStar(indent, pkg, in string, e Expr, onlyIf string) (jok, gol, goc, out string) {
+	if onlyIf == "" {
+		onlyIf = in + " != nil"
+	} else {
+		onlyIf = in + " != nil && " + onlyIf
+	}
+	jok, gol, goc, out = g.genGoPostExpr(indent, pkg, "(*"+in+")", e, onlyIf)
+	gol = "*" + gol
+	return
+}
+
+// Returns the Go type (as named in generated code) of e, if a struct
+// param (or pointer to one) that may be passed a Joker map or handle,
+// else "".
+func (g *Generator) structParam(pkg string, e Expr) string {
+	if !g.roundTrip {
+		return ""
+	}
+	t := e
+	if v, ok := e.(*StarExpr); ok {
+		t = v.X
+	}
+	id, ok := t.(*Ident)
+	if !ok {
+		return ""
+	}
+	ti, found := g.types[pkg+"."+id.Name]
+	if !found || ti.td.TypeParams != nil {
+		return ""
+	}
+	if _, ok := ti.td.Type.(*StructType); !ok {
+		return ""
+	}
+	return g.goTypeName(pkg, e)
+}
+
+// Generates code that converts each struct param (passed from Joker
+// as a map or handle) to _param, starting with the Go value (if any)
+// the map was converted from.
+func (g *Generator) genStructParams(indent, pkg string, fl *FieldList) (code string) {
+	for _, f := range fl.List {
+		t := g.structParam(pkg, f.Type)
+		if t == "" {
+			continue
+		}
+		for _, n := range f.Names {
+			p := paramNameAsGo(n.Name)
+			code += indent + "var _" + p + " " + t + "\n" +
+				indent + runtimePrefix + "ToGoValue(" + p + ", &_" + p + ")\n"
+		}
+	}
+	return
+}
+
+// Returns Go code converting the Joker object v to the (scalar) Go type e, or "" if not supported.
+func (g *Generator) jokerToGo(pkg string, e Expr, v string) string {
+	if c := g.customConverter(pkg, e); c != nil {
+		return g.useConverter(pkg, e).JokerToGo(v)
+	}
+	t, ok := e.(*Ident)
+	if !ok {
+		return ""
+	}
+	underlying := t.Name
+	goType := t.Name
+	if ti, found := g.types[pkg+"."+t.Name]; found {
+		u, ok := ti.td.Type.(*Ident)
+		if !ok {
+			return ""
+		}
+		underlying = u.Name
+		goType = "_" + path.Base(pkg) + "." + t.Name
+	}
+	if c, ok := builtinConverters[underlying]; ok && c.of != "" {
+		return goType + "(" + runtimePrefix + c.of + "(" + v + "))"
+	}
+	return ""
+}
+
+// Generates code that, at run time, returns a reference whose deref yields the current value pointed to by in,
+// and whose reset modifies that value (converting a map or handle to a struct as for --round-trip params).
+func (g *Generator) genGoPostRef(indent, pkg, in string, e Expr) (jok, gol, goc, out string) {
+	var getGoc, getOut string
+	if g.foreignType(pkg, e) { // E.g. the time.Duration of an out-param
+		jok, gol, getOut = "Object", types.ExprString(e), runtimePrefix+"FromGo("+in+")"
+	} else {
+		jok, gol, getGoc, getOut = g.genGoPostExpr(indent+"\t\t", pkg, "(*"+in+")", e, "")
+	}
+	jok = "(ref " + jok + ")"
+	gol = "*" + gol
+	if !exprIsUseful(getOut) {
+		out = "NIL"
+		return
+	}
+	set := "func(_v Object) {\n" +
+		indent + "\t\t" + runtimePrefix + "ToGoValue(_v, " + in + ")\n" +
+		indent + "\t}"
+	if conv := g.jokerToGo(pkg, e, "_v"); conv != "" {
+		set = "func(_v Object) {\n" +
+			indent + "\t\t*" + in + " = " + conv + "\n" +
+			indent + "\t}"
+	}
+	out = "_ref" + g.genSym("")
+	goc = indent + "var " + out + " Object = NIL\n" +
+		indent + "if " + in + " != nil {\n" +
+		indent + "\t" + out + " = " + runtimePrefix + "MakeRef(func() Object {\n" +
+		getGoc +
+		indent + "\t\treturn " + getOut + "\n" +
+		indent + "\t}, " + set + ")\n" +
+		indent + "}\n"
+	return
+}
+
+func maybeNil(expr, in string) string {
+	return "func () Object { if (" + expr + ") == nil { return NIL } else { return " + in + " } }()"
+}
+
+func (g *Generator) genGoPostExpr(indent, pkg, in string, e Expr, onlyIf string) (jok, gol, goc, out string) {
+	if isEmptyInterface(e) {
+		jok = "Object"
+		gol = "interface{}"
+		out = runtimePrefix + "FromGo(" + in + ")"
+		return
+	}
+	if c := g.customConverter(pkg, e); c != nil && c.GoToJoker(in) != "" {
+		g.useConverter(pkg, e)
+		jok = c.JokerType()
+		gol = c.GoType()
+		out = c.GoToJoker(in)
+		return
+	}
+	if t := qualifiedTypeName(e); streamResults[t] || isContext(e) {
+		jok = "GoObject"
+		gol = t
+		out = runtimePrefix + "MakeGoObject(" + in + ")"
+		return
+	}
+	switch v := e.(type) {
+	case *Ident:
+		if c := g.builtinConverter(pkg, e); c != nil && c.post != "" {
+			jok = c.JokerType()
+			gol = c.GoType()
+			out = c.GoToJoker(in)
+			return
+		}
+		switch v.Name {
+		case "error":
+			jok = "Error"
+			gol = "error"
+			if g.typedErrors {
+				out = maybeNil(in, runtimePrefix+"MakeGoError("+in+")")
+			} else {
+				out = maybeNil(in, "MakeError("+in+")") // TODO: Test this against the MakeError() added to joker/core/object.go
+			}
+		default:
+			jok, _, goc, out = g.genGoPostNamed(indent, pkg, in, v, onlyIf)
+			gol = v.Name // This is as far as Go needs to go for a type signature
+		}
+	case *ArrayType:
+		jok, gol, goc, out = g.genGoPostArray(indent, pkg, in, v.Elt, onlyIf)
+	case *StarExpr:
+		jok, gol, goc, out = g.genGoPostStar(indent, pkg, in, v.X, onlyIf)
+	case *StructType:
+		jok, gol, goc, out = g.genGoPostStruct(indent, pkg, in, v.Fields, onlyIf)
+	default:
+		jok = g.abendAt("883", e.Pos(), g.diagType(pkg, e), fmt.Sprintf("unrecognized Expr type %T", e))
+		gol = "..."
+		out = in
+	}
+	return
+}
+
+const resultName = "_res"
+
+func (g *Generator) genGoPostItem(indent, pkg, in string, f *Field, onlyIf string) (captureVar, jok, gol, goc, out string, useful bool) {
+	captureVar = in
+	if in == "" {
+		captureVar = g.genSym(resultName)
+	}
+	if s, ok := f.Type.(*StarExpr); ok && g.pointerRefs && !streamResults[qualifiedTypeName(s)] {
+		jok, gol, goc, out = g.genGoPostRef(indent, pkg, captureVar, s.X)
+	} else {
+		jok, gol, goc, out = g.genGoPostExpr(indent, pkg, captureVar, f.Type, onlyIf)
+	}
+	if in != "" && in != resultName {
+		gol = paramNameAsGo(in) + " " + gol
+	}
+	useful = exprIsUseful(out)
+	if !useful && !g.rawResults { // Else it's still returned as a handle
+		captureVar = "_"
+	}
+	return
+}
+
+func reverseJoin(a []string, infix string) string {
+	j := ""
+	for idx := len(a) - 1; idx >= 0; idx-- {
+		if idx != len(a)-1 {
+			j += infix
+		}
+		j += a[idx]
+	}
+	return j
+}
+
+// Generates code that, at run time, tests each of the onlyIf's and, if all true, returns the expr; else returns NIL.
+func wrapOnlyIfs(onlyIf string, e string) string {
+	if len(onlyIf) == 0 {
+		return e
+	}
+	return "func() Object { if " + onlyIf + " { return " + e + " } else { return NIL } }()"
+}
+
+// Add one level of indent to each line
+func indentedCode(c string) string {
+	return "\t" + strings.Replace(c, "\n", "\n\t", -1)
+}
+
+func wrapStmtOnlyIfs(indent, v, t, e string, onlyIf string, c string, out *string) string {
+	if len(onlyIf) == 0 {
+		*out = v
+		return indent + v + " := " + e + "\n" + c
+	}
+	*out = "_obj" + v
+	return indent + "var " + *out + " Object\n" +
+		indent + "if " + onlyIf + " {\n" +
+		indent + "\t" + v + " := " + e + "\n" +
+		strings.TrimRight(indentedCode(c), "\t") +
+		indent + "\t" + *out + " = Object(" + v + ")\n" +
+		indent + "} else {\n" +
+		indent + "\t" + *out + " = NIL\n" +
+		indent + "}\n"
+}
+
+func isErrorType(e Expr) bool {
+	v, ok := e.(*Ident)
+	return ok && v.Name == "error"
+}
+
+func countFields(fl FieldList) (n int) {
+	for _, f := range fl.List {
+		if f.Names == nil {
+			n++
+		} else {
+			n += len(f.Names)
+		}
+	}
+	return
+}
+
+// Generates code that, at run time, throws a non-nil error (returned by the Go function fn) as a Joker exception.
+func genThrowError(indent, fn, captureVar string) string {
+	return indent + "if " + captureVar + " != nil {\n" +
+		indent + "\t" + runtimePrefix + "ThrowError(\"" + fn + "\", " + captureVar + ")\n" +
+		indent + "}\n"
+}
+
+// Caller generates "outGOCALL;goc" while saving jok and gol for type info (they go into .joke as metadata and docstrings).
+// If throwFn is nonempty, a trailing error result is thrown (as a Joker exception on behalf of throwFn) instead of returned.
+// The (already-declared) out-parameter variables described by outs are returned after the results.
+func (g *Generator) genGoPostList(indent string, pkg string, fl FieldList, throwFn string, outs []*Field) (jok, gol, goc, out string) {
+	useful := false
+	captureVars := []string{}
+	rawVars := []string{}
+	jokType := []string{}
+	golType := []string{}
+	goCode := []string{}
+	throwCode := ""
+	resultPos, resultType := token.NoPos, diagType{} // Of the (last) result, for diagnostics
+
+	result := resultName
+	multipleCaptures := len(fl.List) > 1 || (len(fl.List) == 1 && fl.List[0].Names != nil && len(fl.List[0].Names) > 1)
+	numResults := countFields(fl) + len(outs)
+	throwIdx := -1
+	if throwFn != "" && returnsError(&fl) {
+		throwIdx = countFields(fl) - 1
+		numResults--
+		useful = numResults == 0 // If nothing else is returned, the caller still learns of success or failure
+	}
+	multipleResults := numResults > 1
+	mapResults := multipleResults && g.resultsAsMap
+	resultKeys := []string{}
+	addResult := func(key, out string) string {
+		resultKeys = append(resultKeys, key)
+		if mapResults {
+			return indent + result + ".Add(MakeKeyword(\"" + key + "\"), " + out + ")\n"
+		}
+		return indent + result + " = " + result + ".Conjoin(" + out + ")\n"
+	}
+	idx := 0
+	for _, f := range fl.List {
+		names := []string{}
+		if f.Names == nil {
+			names = append(names, "")
+		} else {
+			for _, n := range f.Names {
+				names = append(names, n.Name)
+			}
+		}
+		for _, n := range names {
+			captureName := result
+			if multipleCaptures || multipleResults { // Else result names the vector (or map) of results
+				captureName = n
+			}
+			if idx == throwIdx {
+				captureVar := captureName
+				gol := "error"
+				if captureVar == "" {
+					captureVar = g.genSym(resultName)
+				} else if captureVar != resultName {
+					gol = paramNameAsGo(captureVar) + " " + gol
+				}
+				throwCode = genThrowError(indent, throwFn, captureVar)
+				captureVars = append(captureVars, captureVar)
+				golType = append(golType, gol)
+				idx++
+				continue
+			}
+			captureVar, jok, gol, goc, out, usefulItem := g.genGoPostItem(indent, pkg, captureName, f, "")
+			useful = useful || usefulItem
+			resultPos, resultType = f.Type.Pos(), g.diagType(pkg, f.Type)
+			rawVars = append(rawVars, captureVar)
+			if multipleResults {
+				key := n
+				if key == "" {
+					key = fmt.Sprintf("result%d", idx+1)
+				}
+				goc += addResult(key, out)
+				if idx == countFields(fl)-1 && isErrorType(f.Type) { // Where a future finds the (returned) error
+					if mapResults {
+						g.errorResult = "MakeKeyword(\"" + key + "\")"
+					} else {
+						g.errorResult = fmt.Sprintf("MakeInt(%d)", idx)
+					}
+				}
+			} else {
+				result = out
+			}
+			captureVars = append(captureVars, captureVar)
+			jokType = append(jokType, jok)
+			golType = append(golType, gol)
+			goCode = append(goCode, goc)
+			idx++
+		}
+	}
+	for _, f := range outs {
+		var jok, goc, out string
+		v := paramNameAsGo(f.Names[0].Name)
+		switch {
+		case isDynamicOut(f.Type):
+			v = "_" + v // As allocated by genOutParams
+			conv := "FromOut"
+			if isDynamicVariadic(f.Type) {
+				conv = "FromOuts"
+			}
+			jok, out = "Object", runtimePrefix+conv+"("+v+")"
+		case g.outRefs: // Go might retain the pointer (e.g. flag.StringVar), so return a live reference
+			jok, _, goc, out = g.genGoPostRef(indent, pkg, "(&"+v+")", f.Type)
+		case g.foreignType(pkg, f.Type):
+			jok, out = "Object", runtimePrefix+"FromGo("+v+")"
+		default:
+			_, jok, _, goc, out, _ = g.genGoPostItem(indent, pkg, f.Names[0].Name, f, "")
+		}
+		useful = useful || exprIsUseful(out)
+		resultPos, resultType = f.Names[0].Pos(), g.diagType(pkg, f.Type)
+		if multipleResults {
+			goc += addResult(f.Names[0].Name, out)
+		} else {
+			result = out
+		}
+		rawVars = append(rawVars, v)
+		jokType = append(jokType, jok)
+		goCode = append(goCode, goc)
+	}
+
+	out = strings.Join(captureVars, ", ")
+	if out != "" {
+		out += " := "
+	}
+
+	jok = strings.Join(jokType, " ")
+	if mapResults {
+		pairs := []string{}
+		for i, k := range resultKeys {
+			pairs = append(pairs, ":"+k+" "+jokType[i])
+		}
+		jok = "{" + strings.Join(pairs, ", ") + "}"
+	} else if len(jokType) > 1 && jok != "" {
+		jok = "[" + jok + "]"
+	}
+
+	gol = strings.Join(golType, ", ")
+	if len(golType) > 1 && gol != "" {
+		gol = "(" + gol + ")"
+	}
+
+	goc = strings.Join(goCode, "")
+
+	if g.rawResults && numResults > 0 {
+		throwCode += genRawResults(indent, rawVars)
+	}
+
+	if numResults == 0 {
+		goc = throwCode + indent + "return NIL\n"
+	} else if multipleResults {
+		if useful {
+			empty := "EmptyVector"
+			if mapResults {
+				empty = "EmptyArrayMap()"
+			}
+			goc = throwCode + indent + result + " := " + empty + "\n" + goc + indent + "return " + result + "\n"
+		} else {
+			goc = indent + g.abend("123", fl.Pos(), diagType{}, "no public information returned") + "\n"
+		}
+	} else {
+		goc = throwCode + goc
+		if goc == "" && (result == resultName || !useful) {
+			out = "return " // No code generated, nor conversion needed, so no need to use intermediary
+		} else {
+			goc += indent + "return " + result + "\n"
+		}
+		if !useful {
+			goc += indent + g.abend("124", resultPos, resultType, "no public information returned") + "\n"
+		}
+	}
+
+	return
+}
+
+// Generates code that, when go.gostd/*raw-results* is true, returns
+// the Go values in vars (one handle, or a vector of them) unconverted.
+func genRawResults(indent string, vars []string) string {
+	return indent + "if " + runtimePrefix + "RawResults() {\n" +
+		indent + "\treturn " + runtimePrefix + "RawResult(" + strings.Join(vars, ", ") + ")\n" +
+		indent + "}\n"
+}
+
+// Return a form of the return type as supported by generate-std.joke,
+// or empty string if not supported (which will trigger attempting to
+// generate appropriate code for *_native.go). gol either passes
+// through or "Object" is returned for it if jok is returned as empty.
+func jokerReturnTypeForGenerateSTD(in_jok, in_gol string) (jok, gol string) {
+	switch in_jok {
+	case "String", "Int", "Byte", "Double", "Bool", "Time", "Error": // TODO: Have tested only String so far
+		jok = `^"` + in_jok + `"`
+	default:
+		jok = ""
+		gol = "Object"
+	}
+	return
+}
+
+type codeInfo map[string]string
+
+func sortedPackageMap(m map[string]codeInfo, f func(k string, v codeInfo)) {
+	var keys []string
+	for k, _ := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f(k, m[k])
+	}
+}
+
+func sortedCodeMap(m codeInfo, f func(k string, v string)) {
+	var keys []string
+	for k, _ := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f(k, m[k])
+	}
+}
+
+var nonEmptyLineRegexp *regexp.Regexp
+
+type funcCode struct {
+	jokerParamList        string // fieldListAsClojure(d.Type.Params)
+	goParamList           string // paramListAsGo(d.Type.Params)
+	jokerGoParams         string // "(" + fieldListToGo(d.Type.Params) + ")"
+	goCode                string
+	jokerReturnTypeForDoc string // genReturnType(pkg, d.Type.Results)
+	goReturnTypeForDoc    string // genReturnType(pkg, d.Type.Results)
+}
+
+func (g *Generator) genGoPre(indent, pkg string, fl *FieldList, goFname string, outs map[string]bool) (jok, jok2golParams, gol, code, params string, outFields []*Field) {
+	code, outFields = g.genOutParams(indent, pkg, fl, outs) // TODO: enhance to support composites
+	code += genContextParams(indent, fl)
+	code += g.genCallbackParams(indent, pkg, fl)
+	code += g.genStructParams(indent, pkg, fl)
+	visible := withoutParams(fl, hiddenOuts(fl, outs))
+	jok = g.fieldListAsClojure(pkg, visible)
+	jok2golParams = "(" + g.fieldListToGo(visible) + ")"
+	gol = g.paramListAsGo(pkg, visible)
+	params = g.argsAsGo(pkg, fl, outs)
+	return
+}
+
+/* Maps qualified function names to the names of their pointer
+/* parameters through which results are returned ("out-parameters").
+/* The wrapper allocates each such parameter itself, passes its
+/* address, and returns its final value after the function's own
+/* results. Extended/overridden via Options.OutParams. */
+var defaultOutParams = map[string][]string{
+	"encoding/binary.Read":    {"data"},
+	"encoding/json.Unmarshal": {"v"},
+	"encoding/xml.Unmarshal":  {"v"},
+	"flag.BoolVar":            {"p"},
+	"flag.DurationVar":        {"p"},
+	"flag.Float64Var":         {"p"},
+	"flag.Int64Var":           {"p"},
+	"flag.IntVar":             {"p"},
+	"flag.StringVar":          {"p"},
+	"flag.Uint64Var":          {"p"},
+	"flag.UintVar":            {"p"},
+	"fmt.Fscan":               {"a"},
+	"fmt.Fscanf":              {"a"},
+	"fmt.Fscanln":             {"a"},
+	"fmt.Sscan":               {"a"},
+	"fmt.Sscanf":              {"a"},
+	"fmt.Sscanln":             {"a"},
+}
+
+/* Functions that retain their out-parameters, updating them later
+/* (as flag.Parse does), so they're returned as references (as with
+/* --pointer-refs) whose derefs yield their current values. */
+var retainedOutParams = map[string]bool{
+	"flag.BoolVar":     true,
+	"flag.DurationVar": true,
+	"flag.Float64Var":  true,
+	"flag.Int64Var":    true,
+	"flag.IntVar":      true,
+	"flag.StringVar":   true,
+	"flag.Uint64Var":   true,
+	"flag.UintVar":     true,
+}
+
+// Whether an out-param of type e is an interface{} (or ...interface{}),
+// for which a Go value is allocated per the arg passed for it.
+func isDynamicOut(e Expr) bool {
+	return isEmptyInterface(e) || isDynamicVariadic(e)
+}
+
+// Returns those of outs that are hidden from Joker callers: all but
+// the interface{} ones, whose args say what to allocate.
+func hiddenOuts(fl *FieldList, outs map[string]bool) map[string]bool {
+	hidden := map[string]bool{}
+	for p, _ := range outs {
+		if !isDynamicOut(paramType(fl, p)) {
+			hidden[p] = true
+		}
+	}
+	return hidden
+}
+
+// Parses "pkg.Func:param[+param...]", adding to (or, if no params are listed, clearing) outParams.
+func (g *Generator) addOutParams(spec string) error {
+	idx := strings.LastIndex(spec, ":")
+	if idx == -1 {
+		return fmt.Errorf("missing ':' in out-param %s", spec)
+	}
+	fn := spec[0:idx]
+	if spec[idx+1:] == "" {
+		delete(g.outParams, fn)
+		return nil
+	}
+	g.outParams[fn] = append(g.outParams[fn], strings.Split(spec[idx+1:], "+")...)
+	return nil
+}
+
+func (g *Generator) outParamsFor(f string) map[string]bool {
+	outs := map[string]bool{}
+	for _, p := range g.outParams[f] {
+		if g.invalidOutParams[f+":"+p] {
+			continue // Already warned of
+		}
+		outs[p] = true
+	}
+	return outs
+}
+
+// Returns a copy of fl omitting the named parameters.
+func withoutParams(fl *FieldList, omit map[string]bool) *FieldList {
+	if len(omit) == 0 {
+		return fl
+	}
+	res := &FieldList{}
+	for _, f := range fl.List {
+		if f.Names == nil {
+			res.List = append(res.List, f)
+			continue
+		}
+		names := []*Ident{}
+		for _, n := range f.Names {
+			if !omit[n.Name] {
+				names = append(names, n)
+			}
+		}
+		if len(names) > 0 {
+			nf := *f
+			nf.Names = names
+			res.List = append(res.List, &nf)
+		}
+	}
+	return res
+}
+
+// Returns the Go type (as named in generated code) for e, or "" if not supported.
+func (g *Generator) goTypeName(pkg string, e Expr) string {
+	if isEmptyInterface(e) {
+		return "interface{}"
+	}
+	switch v := e.(type) {
+	case *Ident:
+		if _, found := g.types[pkg+"."+v.Name]; found {
+			return "_" + path.Base(pkg) + "." + v.Name
+		}
+		switch v.Name {
+		case "string", "bool", "byte", "rune", "error", "float32", "float64",
+			"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return v.Name
+		}
+	case *StarExpr:
+		if t := g.goTypeName(pkg, v.X); t != "" {
+			return "*" + t
+		}
+	case *ArrayType:
+		if t := g.goTypeName(pkg, v.Elt); t != "" && v.Len == nil {
+			return "[]" + t
+		}
+	}
+	return ""
+}
+
+// Returns the Go type (as named in generated code) for e, if it's
+// defined by a package imported by the file declaring the function
+// being generated (noting the import), else "".
+func (g *Generator) importedTypeName(e Expr) string {
+	v, ok := e.(*SelectorExpr)
+	if !ok {
+		return ""
+	}
+	x, ok := v.X.(*Ident)
+	if !ok || isPrivate(v.Sel.Name) {
+		return ""
+	}
+	p, found := g.fileImports[x.Name]
+	if !found {
+		return ""
+	}
+	g.importsUsed = append(g.importsUsed, p)
+	return "_" + path.Base(p) + "." + v.Sel.Name
+}
+
+// Whether e is a type defined by another package, for which there's no (custom) converter.
+func (g *Generator) foreignType(pkg string, e Expr) bool {
+	_, ok := e.(*SelectorExpr)
+	return ok && g.customConverter(pkg, e) == nil
+}
+
+var majorVersionRegexp = regexp.MustCompile(`^v[0-9]+$`)
+
+// Maps the names of the packages imported by f to their paths.
+func fileImports(f *File) map[string]string {
+	imports := map[string]string{}
+	for _, s := range f.Imports {
+		p, err := strconv.Unquote(s.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(p)
+		if s.Name != nil {
+			name = s.Name.Name
+		} else if majorVersionRegexp.MatchString(name) && strings.Contains(p, "/") { // E.g. "math/rand/v2"
+			name = path.Base(path.Dir(p))
+		}
+		imports[name] = p
+	}
+	return imports
+}
+
+// Returns e as written in Go source, but with the names of types
+// defined by pkg qualified by its base name (e.g. "*url.URL"), for
+// diagnostics.
+func (g *Generator) typeString(pkg string, e Expr) string {
+	switch v := e.(type) {
+	case *Ident:
+		if _, found := g.types[pkg+"."+v.Name]; found {
+			return path.Base(pkg) + "." + v.Name
+		}
+	case *StarExpr:
+		return "*" + g.typeString(pkg, v.X)
+	case *ArrayType:
+		if v.Len == nil {
+			return "[]" + g.typeString(pkg, v.Elt)
+		}
+	}
+	return types.ExprString(e)
+}
+
+// A Go type, as a Diagnostic reports it.
+type diagType struct {
+	name string // As typeString returns it, e.g. "*url.URL"
+	path string // The named type it's of, qualified by package path (e.g. "net/url.URL"), if known
+}
+
+// Returns the diagType of the type e, in a function of the package.
+func (g *Generator) diagType(pkg string, e Expr) diagType {
+	return diagType{g.typeString(pkg, e), g.typePath(pkg, e)}
+}
+
+// Returns the diagType of the type t defined by the package.
+func localType(pkg, t string) diagType {
+	return diagType{path.Base(pkg) + "." + t, pkg + "." + t}
+}
+
+// Returns the named type that e (less any * or [] prefixes) is of,
+// qualified by the path, not just the name, of its package (so that,
+// e.g., text/template.Template and html/template.Template differ), or
+// "" if there's none such.
+func (g *Generator) typePath(pkg string, e Expr) string {
+	switch v := e.(type) {
+	case *Ident:
+		if _, found := g.types[pkg+"."+v.Name]; !found && types.Universe.Lookup(v.Name) != nil {
+			return v.Name // E.g. float64
+		}
+		return pkg + "." + v.Name
+	case *SelectorExpr:
+		if x, ok := v.X.(*Ident); ok {
+			if p, found := g.fileImports[x.Name]; found {
+				return p + "." + v.Sel.Name
+			}
+		}
+	case *StarExpr:
+		return g.typePath(pkg, v.X)
+	case *ArrayType:
+		if v.Len == nil {
+			return g.typePath(pkg, v.Elt)
+		}
+	}
+	return ""
+}
+
+// Generates declarations of the variables whose addresses are passed as out-parameters, and fields describing them.
+func (g *Generator) genOutParams(indent, pkg string, fl *FieldList, outs map[string]bool) (code string, fields []*Field) {
+	for _, f := range fl.List {
+		for _, n := range f.Names {
+			if !outs[n.Name] {
+				continue
+			}
+			if isDynamicOut(f.Type) {
+				conv := "NewOut"
+				if isDynamicVariadic(f.Type) {
+					conv = "NewOuts"
+				}
+				code += indent + "_" + paramNameAsGo(n.Name) + " := " + runtimePrefix + conv + "(" + paramNameAsGo(n.Name) + ")\n"
+				fields = append(fields, &Field{Names: []*Ident{n}, Type: f.Type})
+				continue
+			}
+			t := ""
+			s, ok := f.Type.(*StarExpr)
+			if ok {
+				t = g.goTypeName(pkg, s.X)
+				if t == "" {
+					t = g.importedTypeName(s.X)
+				}
+			}
+			if t == "" {
+				code += indent + g.abendAt("731", n.Pos(), g.diagType(pkg, f.Type), "unsupported out-parameter "+n.Name) + "\n"
+				continue
+			}
+			code += indent + "var " + paramNameAsGo(n.Name) + " " + t + "\n"
+			fields = append(fields, &Field{Names: []*Ident{n}, Type: s.X})
+		}
+	}
+	return
+}
+
+func genGoCall(pkg, goFname string, goParams string) string {
+	return "_" + pkg + "." + goFname + "(" + goParams + ")\n"
+}
+
+func (g *Generator) genGoPost(indent string, pkg string, d *FuncDecl, throwFn string, outs []*Field) (goResultAssign, jokerReturnTypeForDoc, goReturnTypeForDoc string, goReturnCode string) {
+	fl := d.Type.Results
+	if (fl == nil || fl.List == nil) && len(outs) == 0 {
+		return
+	}
+	if fl == nil {
+		fl = &FieldList{}
+	}
+	jokerReturnTypeForDoc, goReturnTypeForDoc, goReturnCode, goResultAssign = g.genGoPostList(indent, pkg, *fl, throwFn, outs)
+	return
+}
+
+func (g *Generator) genFuncCode(pkgBaseName, pkgDirUnix string, d *FuncDecl, goFname, throwFn string, outs map[string]bool, voidOK bool) (fc funcCode) {
+	var goPreCode, goParams, goResultAssign, goPostCode string
+	var outFields []*Field
+
+	fc.jokerParamList, fc.jokerGoParams, fc.goParamList, goPreCode, goParams, outFields =
+		g.genGoPre("\t", pkgDirUnix, d.Type.Params, goFname, outs)
+	goCall := genGoCall(pkgBaseName, d.Name.Name, goParams)
+	goResultAssign, fc.jokerReturnTypeForDoc, fc.goReturnTypeForDoc, goPostCode =
+		g.genGoPost("\t", pkgDirUnix, d, throwFn, outFields)
+
+	if goPostCode == "" && goResultAssign == "" {
+		if voidOK {
+			goPostCode = "\treturn NIL\n" // E.g. a call made asynchronously, or for its calls back to Joker
+		} else {
+			goPostCode = "\t" + g.abend("675", d.Pos(), diagType{}, "no results to return") + "\n"
+		}
+	}
+
+	fc.goCode = goPreCode + // Optional block of pre-code
+		"\t" + goResultAssign + goCall + // [results := ]fn-to-call([args...])
+		goPostCode // Optional block of post-code
+	return
+}
+
+// If the Go API returns a single result, and it's an Int, wrap the call in "int()" (unless the call is to a native
+// wrapper, which returns an Object). If a StarExpr is found, ABEND for now
+// TODO: Return ref's for StarExpr?
+func (g *Generator) maybeConvertGoResult(pkg, call string, fl *FieldList, native bool) string {
+	if fl == nil || len(fl.List) != 1 || (fl.List[0].Names != nil && len(fl.List[0].Names) > 1) {
+		return call
+	}
+	named := false
+	t := fl.List[0].Type
+	for {
+		stop := false
+		switch v := t.(type) {
+		case *Ident:
+			qt := pkg + "." + v.Name
+			if v, ok := g.types[qt]; ok {
+				named = true
+				t = v.td.Type
+			} else {
+				stop = true
+			}
+		default:
+			stop = true
+		}
+		if stop {
+			break
+		}
+	}
+	switch v := t.(type) {
+	case *Ident:
+		switch v.Name {
+		case "int16", "uint", "uint16", "int32", "uint32", "int64", "byte": // TODO: Does Joker always have 64-bit signed ints?
+			if !native {
+				return "int(" + call + ")"
+			}
+		case "int":
+			if named && !native {
+				return "int(" + call + ")"
+			} // Else it's already an int, so don't bother wrapping it.
+		}
+	case *StarExpr:
+		if !g.pointerRefs {
+			return g.abend("401", fl.List[0].Type.Pos(), g.diagType(pkg, fl.List[0].Type), "StarExpr not supported -- no refs returned just yet: "+call)
+		}
+	}
+	return call
+}
+
+// An unsupported construct found while generating code, and the text marking it therein.
+type pendingAbend struct {
+	Diagnostic
+	pos    token.Pos
+	marker string
+}
+
+// Records an unsupported construct (at pos, involving typ, a Go
+// type, if known) found while generating code, returning the text
+// marking it in that code: ABENDnnn(msg). Such a marker comments out
+// the function whose code contains it, so code that's discarded
+// instead (see genGoPostData) discards its pending ABENDs too.
+func (g *Generator) abend(code string, pos token.Pos, typ diagType, msg string) string {
+	return g.recordAbend(code, pos, typ, msg, "ABEND"+code+"("+msg+")")
+}
+
+// Same as abend, but the marker also shows where the construct is: ABENDnnn(msg at: file:line:col).
+func (g *Generator) abendAt(code string, pos token.Pos, typ diagType, msg string) string {
+	return g.recordAbend(code, pos, typ, msg, "ABEND"+code+"("+msg+" at: "+unix(g.whereAt(pos))+")")
+}
+
+func (g *Generator) recordAbend(code string, pos token.Pos, typ diagType, msg, marker string) string {
+	d := Diagnostic{Severity: Unsupported, Code: code, Type: typ.name, TypePath: typ.path, Message: msg}
+	if pos.IsValid() {
+		p := g.fset.Position(pos)
+		d.File, d.Line, d.Column = unix(p.Filename), p.Line, p.Column
+	}
+	g.pending = append(g.pending, pendingAbend{d, pos, marker})
+	return marker
+}
+
+// Reports the (distinct) unsupported constructs preventing generation
+// of f, those marked in its code (some pieces of which, such as the
+// Go parameter list of a function called directly by Joker, go
+// unused). Returns whether there are any.
+func (g *Generator) trackAbends(f string, code string) bool {
+	seen := map[Diagnostic]bool{}
+	for _, a := range g.pending {
+		if seen[a.Diagnostic] || !strings.Contains(code, a.marker) {
+			continue // E.g. a param's type, when generating more than one arity
+		}
+		seen[a.Diagnostic] = true
+		d := a.Diagnostic
+		d.Function = f
+		g.abends[d.Code]++
+		g.functionAbends[f] = append(g.functionAbends[f], d.Code)
+		g.diagnostics = append(g.diagnostics, d)
+	}
+	return len(seen) != 0
+}
+
+// Whether a function with the given results returns, as its last result, an error.
+func returnsError(fl *FieldList) bool {
+	return fl != nil && len(fl.List) > 0 && isErrorType(fl.List[len(fl.List)-1].Type)
+}
+
+// Documents, for functions passed Joker fns for Go to call back, when
+// the Joker evaluator (which isn't safe for concurrent use) lets them run.
+const callbackDoc = "Joker fns passed for callbacks run (one at a time) only while the Joker thread waits on a Go call, such as this one, or on a future (via go.gostd/await or deref). So, e.g., a handler that Go calls after this returns won't run while the REPL is idle: wait on (go.gostd/await f) for a future f, such as one returned by a function generated with --async."
+
+// Wraps goCode, the body of a native wrapper, so it runs
+// asynchronously, having first (on the Joker thread) checked for raw
+// results, and making any exceptions via the support package's Async
+// variants.
+func (g *Generator) genAsync(f, goCode string) string {
+	pre := ""
+	if raw := runtimePrefix + "RawResults()"; strings.Contains(goCode, raw) {
+		v := g.genSym("_raw")
+		pre = "\t" + v + " := " + raw + "\n"
+		goCode = strings.Replace(goCode, raw, v, -1)
+	}
+	for _, fn := range []string{"ThrowError", "MakeGoError"} { // Which run Joker code, so must wait their turn
+		goCode = strings.Replace(goCode, runtimePrefix+fn+"(", runtimePrefix+fn+"Async(", -1)
+	}
+	return pre + "\treturn " + runtimePrefix + "Async(\"" + f + "\", " + g.errorResult + ", func() Object {\n" +
+		nonEmptyLineRegexp.ReplaceAllString(goCode, "\t$1") +
+		"\t})\n"
+}
+
+func (g *Generator) throwsErrors(f string) bool {
+	switch g.functionConfig(f).Errors {
+	case "throw":
+		return true
+	case "return":
+		return false
+	}
+	if g.errorsAsExceptions || g.errorsAsExceptionsFor[f] {
+		return true
+	}
+	fn := g.qualifiedFunctions[f]
+	return fn != nil && g.errorsAsExceptionsFor[fn.pkgDirUnix]
+}
+
+// Generates code that adds the (convertible) exported fields of in, a struct, to the map named by data.
+func (g *Generator) genErrorFields(indent, pkg, in string, fl *FieldList) (goc string) {
+	for _, f := range fl.List {
+		for _, p := range f.Names {
+			if isPrivate(p.Name) {
+				continue
+			}
+			mark := len(g.pending)
+			_, _, more_goc, out := g.genGoPostExpr(indent, pkg, in+"."+p.Name, f.Type, "")
+			if len(g.pending) != mark || !exprIsUseful(out) {
+				g.pending = g.pending[:mark]
+				continue // Skipping unsupported fields
+			}
+			goc += more_goc
+			goc += indent + "data.Add(MakeKeyword(\"" + p.Name + "\"), " + out + ")\n"
+		}
+	}
+	return
+}
+
+// Generates, for a package defining error types, a function that
+// adds the exported fields of such errors to a map, and registers it
+// with the support package.
+func (g *Generator) genErrorData(pkgDirUnix string) string {
+	g.genSymReset()
+	pkgBaseName := path.Base(pkgDirUnix)
+	cases := ""
+	used := false // Whether any case refers to _e
+	sortedErrorTypes(g.errorTypes,
+		func(t string, ptr bool) {
+			name := strings.TrimPrefix(t, pkgDirUnix+".")
+			ti, found := g.types[t]
+			if name == t || !found {
+				return
+			}
+			in := "_e"
+			goType := "_" + pkgBaseName + "." + name
+			if ptr {
+				in = "(*_e)"
+				goType = "*" + goType
+			}
+			cases += "\tcase " + goType + ":\n"
+			if st, ok := ti.td.Type.(*StructType); ok {
+				if fields := g.genErrorFields("\t\t", pkgDirUnix, in, st.Fields); fields != "" {
+					cases += fields
+					used = true
+				}
+			}
+			cases += "\t\treturn true\n"
+		})
+	if cases == "" {
+		return ""
+	}
+	sw := "err.(type)"
+	if used {
+		sw = "_e := " + sw
+	}
+	return `
+func init() {
+	` + runtimePrefix + `RegisterErrorData(errorData)
+}
+
+func errorData(err error, data *ArrayMap) bool {
+	switch ` + sw + ` {
+` + cases + `	}
+	return false
+}
+`
+}
+
+// Generates, for a package defining struct types, registrations of
+// conversions (from such values to Joker maps) with the support
+// package, for use by its dynamic (reflection-based) conversion.
+func (g *Generator) genConverters(pkgDirUnix string) string {
+	pkgBaseName := path.Base(pkgDirUnix)
+	code := ""
+	sortedTypeInfoMap(g.types,
+		func(t string, ti *typeInfo) {
+			name := strings.TrimPrefix(t, pkgDirUnix+".")
+			if name == t || strings.Contains(name, "/") || ti.td.TypeParams != nil {
+				return
+			}
+			if _, ok := ti.td.Type.(*StructType); !ok {
+				return
+			}
+			g.genSymReset()
+			mark, importsMark := len(g.pending), len(g.importsUsed)
+			_, _, goc, out := g.genGoPostExpr("\t\t", pkgDirUnix, "_v", &Ident{Name: name}, "")
+			if len(g.pending) != mark || !exprIsUseful(out) {
+				g.pending, g.importsUsed = g.pending[:mark], g.importsUsed[:importsMark]
+				return // Leave it to reflection
+			}
+			goType := "_" + pkgBaseName + "." + name
+			code += "\t" + runtimePrefix + "RegisterConverter(_reflect.TypeOf((*" + goType + ")(nil)).Elem(), func(_o interface{}) Object {\n" +
+				"\t\t_v := _o.(" + goType + ")\n" +
+				goc +
+				"\t\treturn " + out + "\n" +
+				"\t})\n"
+		})
+	if code == "" {
+		return ""
+	}
+	return `
+func init() {
+` + code + `}
+`
+}
+
+// Generates, for a package defining struct types, registrations of
+// functions looking up their exported fields, so keywords work on
+// handles to them.
+func (g *Generator) genGetters(pkgDirUnix string) string {
+	pkgBaseName := path.Base(pkgDirUnix)
+	code := ""
+	mark, importsMark := len(g.pending), len(g.importsUsed)
+	sortedTypeInfoMap(g.types,
+		func(t string, ti *typeInfo) {
+			name := strings.TrimPrefix(t, pkgDirUnix+".")
+			if name == t || strings.Contains(name, "/") || isPrivate(name) || ti.td.TypeParams != nil {
+				return
+			}
+			st, ok := ti.td.Type.(*StructType)
+			if !ok {
+				return
+			}
+			g.genSymReset()
+			cases := ""
+			for _, f := range st.Fields.List {
+				for _, n := range f.Names {
+					if isPrivate(n.Name) {
+						continue
+					}
+					in := "_v." + n.Name
+					goc, out := g.genGoPostData("\t\t\t", pkgDirUnix, in, f.Type, runtimePrefix+"FromGo("+in+")")
+					cases += "\t\tcase \"" + n.Name + "\":\n" +
+						goc +
+						"\t\t\treturn true, " + out + "\n"
+				}
+			}
+			if cases == "" {
+				return
+			}
+			goType := "_" + pkgBaseName + "." + name
+			code += "\t" + runtimePrefix + "RegisterGetter(_reflect.TypeOf((*" + goType + ")(nil)), func(_o interface{}, _k string) (bool, Object) {\n" +
+				"\t\t_v := _o.(*" + goType + ")\n" +
+				"\t\tswitch _k {\n" +
+				cases +
+				"\t\t}\n" +
+				"\t\treturn false, nil\n" +
+				"\t})\n"
+		})
+	if code == "" || len(g.pending) != mark {
+		g.pending, g.importsUsed = g.pending[:mark], g.importsUsed[:importsMark]
+		return ""
+	}
+	return `
+func init() {
+` + code + `}
+`
+}
+
+// Generates, for each exported (non-generic, non-alias) type defined
+// by the package, a Joker predicate (e.g. go.net.url/URL?) recognizing
+// its values (or, for an interface, those of types implementing it)
+// and, for a struct or interface, maps converted from them. (Other
+// named types, such as time.Duration, convert to plain Joker values,
+// so only handles to them are recognized.) Returns the code
+// registering the types, or "" if there are none.
+func (g *Generator) genTypePredicates(pkgDirUnix string) string {
+	pkgBaseName := path.Base(pkgDirUnix)
+	code := ""
+	sortedTypeInfoMap(g.types,
+		func(t string, ti *typeInfo) {
+			name := strings.TrimPrefix(t, pkgDirUnix+".")
+			// An alias names another type (so its values can't be told
+			// apart), and a generic type no type until instantiated.
+			if name == t || strings.Contains(name, "/") || isPrivate(name) || ti.td.TypeParams != nil || ti.td.Assign.IsValid() {
+				return
+			}
+			code += "\t" + runtimePrefix + "RegisterGoType(_reflect.TypeOf((*_" + pkgBaseName + "." + name + ")(nil)).Elem())\n"
+			var doc string
+			switch ti.td.Type.(type) {
+			case *StructType:
+				doc = "a Go " + t + " (or pointer to one), or a map converted from one"
+			case *InterfaceType:
+				doc = "of a Go type (or pointer to one) implementing " + t + ", or a map converted from one"
+			default: // Converted to Joker values of no Go type, so recognizable only in handles
+				doc = "a handle to a Go " + t + " (or pointer to one)"
+			}
+			g.jokerCode[pkgDirUnix][name+"?"] = fmt.Sprintf(`
+(defn %s?
+  "Returns whether x is %s."
+  {:added "1.0"
+   :go "isGoType(_x, \"%s\")"}
+  [^Object _x])
+`, name, doc, t)
+		})
+	if code == "" {
+		return ""
+	}
+	return `
+func init() {
+` + code + `}
+`
+}
+
+// Whether a (not commented-out) generated function uses the callback adapter type name.
+func (g *Generator) adapterUsed(pkgDirUnix, name string) bool {
+	re := regexp.MustCompile(`(?m)^\t.* := ` + name + `\{`)
+	for _, c := range g.goCode[pkgDirUnix] {
+		if re.MatchString(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) genSupportCode(pkgDirUnix string) {
+	pi := g.packagesInfo[pkgDirUnix]
+	if !pi.nonEmpty {
+		return
+	}
+	sc := codeInfo{}
+	g.convertersUsed = map[string]bool{}
+	g.importsUsed = nil
+	if c := g.genTypePredicates(pkgDirUnix); c != "" {
+		sc["isGoType"] = `
+func isGoType(x Object, t string) Object {
+	return MakeBool(` + runtimePrefix + `IsGoType(x, t))
+}
+` + c
+		pi.importsNative["reflect"] = exists
+	}
+	if g.typedErrors {
+		if c := g.genErrorData(pkgDirUnix); c != "" {
+			sc["errorData"] = c
+		}
+	}
+	for name, c := range g.callbackAdapters[pkgDirUnix] {
+		if g.adapterUsed(pkgDirUnix, name) {
+			sc[name] = c
+		}
+	}
+	if g.dynamicNeeded {
+		if c := g.genConverters(pkgDirUnix); c != "" {
+			sc["converters"] = c
+			pi.importsNative["reflect"] = exists
+		}
+	}
+	if g.handlesNeeded {
+		if c := g.genGetters(pkgDirUnix); c != "" {
+			sc["getters"] = c
+			pi.importsNative["reflect"] = exists
+		}
+	}
+	if len(sc) == 0 {
+		return
+	}
+	g.goSupportCode[pkgDirUnix] = sc
+	pi.importsNative[pkgDirUnix] = exists
+	for _, p := range g.importsUsed { // Of custom converters used by the support code
+		pi.importsNative[p] = exists
+	}
+	pi.importsRuntime = true
+	g.runtimeNeeded = true
+	if _, ok := g.goCode[pkgDirUnix]; !ok {
+		g.goCode[pkgDirUnix] = codeInfo{}
+	}
+}
+
+func (g *Generator) genFunction(f string, fn *funcInfo) error {
+	g.genSymReset()
+	g.pending = nil
+	g.convertersUsed = map[string]bool{}
+	g.resultsAsMap = g.functionConfig(f).Results == "map"
+	d := fn.fd
+	pkgDirUnix := fn.pkgDirUnix
+	pkgBaseName := filepath.Base(pkgDirUnix)
+	goFname := funcNameAsGoPrivate(d.Name.Name)
+	throwFn := ""
+	if g.throwsErrors(f) && returnsError(d.Type.Results) {
+		throwFn = f
+	}
+	outs := g.outParamsFor(f)
+	g.outRefs = g.pointerRefs || retainedOutParams[f]
+	g.errorResult = "NIL"
+	g.fileImports = fileImports(fn.file)
+	g.importsUsed = nil
+	async := g.asyncFunctions[f]
+	callbacks := g.hasCallbackParams(pkgDirUnix, d.Type.Params)
+	fc := g.genFuncCode(pkgBaseName, pkgDirUnix, d, goFname, throwFn, outs, async || callbacks)
+	if callbacks && !async {
+		// Let Go call the Joker fns (from any goroutine) while the Joker thread waits on the call.
+		fc.goCode = "\tdefer " + runtimePrefix + "ReleaseEval()()\n" + fc.goCode
+	}
+	if g.recoverPanics && !async { // Async recovers them anyway
+		fc.goCode = "\tdefer " + runtimePrefix + "RecoverPanic(\"" + f + "\")\n" + fc.goCode
+	}
+	if async {
+		fc.goCode = g.genAsync(f, fc.goCode)
+		if fc.jokerReturnTypeForDoc == "" {
+			fc.jokerReturnTypeForDoc = "GoFuture"
+		} else {
+			fc.jokerReturnTypeForDoc = "GoFuture of " + fc.jokerReturnTypeForDoc
+		}
+	}
+	jokerReturnType, goReturnType := jokerReturnTypeForGenerateSTD(fc.jokerReturnTypeForDoc, fc.goReturnTypeForDoc)
+	raw := strings.Contains(fc.goCode, runtimePrefix+"RawResults()")
+	custom := len(g.convertersUsed) != 0 // generate-std.joke knows nothing of custom converters
+	if throwFn != "" || g.recoverPanics || len(outs) != 0 || async || raw || custom || g.hasConvertedParams(pkgDirUnix, d.Type.Params) { // Must be handled by a *_native.go wrapper
+		jokerReturnType, goReturnType = "", "Object"
+	}
+
+	var jok2gol string
+	if jokerReturnType == "" {
+		jok2gol = goFname
+	} else {
+		jok2gol = pkgBaseName + "." + d.Name.Name
+		if _, found := g.packagesInfo[pkgDirUnix]; !found {
+			return fmt.Errorf("Cannot find package %s", pkgDirUnix)
+		}
+	}
+	jok2golCall := g.maybeConvertGoResult(pkgDirUnix, jok2gol+fc.jokerGoParams, fn.fd.Type.Results, jokerReturnType == "")
+
+	extraDoc := g.functionConfig(f).Doc
+	if callbacks {
+		extraDoc = strings.TrimSpace(extraDoc + "\n\n" + callbackDoc)
+	}
+	jf := &JokeFunction{
+		Name:       g.jokerName(f, fn),
+		Qualified:  f,
+		ReturnType: jokerReturnType,
+		Doc:        strconv.Quote(docString(d.Doc, extraDoc, fc.jokerReturnTypeForDoc, fc.goReturnTypeForDoc)),
+		GoCall:     jok2golCall,
+		Params:     fc.jokerParamList,
+	}
+	jokerFn := ""
+	if ctx := leadingContext(d.Type.Params); ctx != "" {
+		// Also offer an arity that omits the context, passing nil (for a default one) instead.
+		rest := withoutParams(withoutParams(d.Type.Params, hiddenOuts(d.Type.Params, outs)), map[string]bool{ctx: true})
+		restArgs := g.fieldListToGo(rest)
+		if restArgs != "" {
+			restArgs = ", " + restArgs
+		}
+		n := countFields(*rest)
+		jf.ParamsCount, jf.ParamsWithoutCtx, jf.GoCallWithoutCtx = n, g.fieldListAsClojure(pkgDirUnix, rest), goFname+"(NIL"+restArgs+")"
+		jf.ParamsCountWithCtx = n + 1
+		jokerFn = g.execute(jokeFuncContextTemplate, jf)
+	} else {
+		jokerFn = g.execute(jokeFuncTemplate, jf)
+	}
+
+	goFn := ""
+	if jokerReturnType == "" { // TODO: Generate this anyway if it contains ABEND, so we can see what's needed.
+		goFn = g.execute(goFuncTemplate, &GoFunction{
+			Name:       goFname,
+			Qualified:  f,
+			Params:     fc.goParamList,
+			ReturnType: goReturnType,
+			Body:       fc.goCode,
+		})
+	}
+
+	if g.trackAbends(f, jokerFn+goFn) {
+		jokerFn = nonEmptyLineRegexp.ReplaceAllString(jokerFn, `;; $1`)
+		goFn = nonEmptyLineRegexp.ReplaceAllString(goFn, `// $1`)
+	} else {
+		g.generatedFunctions++
+		g.packagesInfo[pkgDirUnix].nonEmpty = true
+		if jokerReturnType == "" {
+			g.packagesInfo[pkgDirUnix].importsNative[pkgDirUnix] = exists
+			for _, p := range g.importsUsed { // Including those of custom converters
+				g.packagesInfo[pkgDirUnix].importsNative[p] = exists
+			}
+			if usesRuntime(goFn) {
+				g.packagesInfo[pkgDirUnix].importsRuntime = true
+				g.runtimeNeeded = true
+			}
+			if strings.Contains(goFn, runtimePrefix+"FromGo(") {
+				g.dynamicNeeded = true
+			}
+			if strings.Contains(goFn, runtimePrefix+"MakeGoObject(") {
+				g.handlesNeeded = true
+			}
+		} else {
+			g.packagesInfo[pkgDirUnix].importsAutoGen[pkgDirUnix] = exists
+		}
+	}
+
+	if _, ok := g.jokerCode[pkgDirUnix]; !ok {
+		g.jokerCode[pkgDirUnix] = codeInfo{}
+	}
+	g.jokerCode[pkgDirUnix][d.Name.Name] = jokerFn
+
+	if _, ok := g.goCode[pkgDirUnix]; !ok {
+		g.goCode[pkgDirUnix] = codeInfo{} // There'll at least be a .joke file
+	}
+	if goFn != "" {
+		g.goCode[pkgDirUnix][d.Name.Name] = goFn
+	}
+	return nil
+}
+
+func (g *Generator) curTimeAndVersion() string {
+	if g.noTimeAndVersion {
+		return "(omitted for testing)"
+	}
+	if g.currentTimeAndVersion == "" {
+		by, _ := time.Now().MarshalText()
+		g.currentTimeAndVersion = string(by) + " by version " + VERSION
+	}
+	return g.currentTimeAndVersion
+}
+
+// E.g.: \t_ "github.com/candid82/joker/std/go/net"
+func (g *Generator) updateJokerMain(pkgs []string, f string) (*OutputFile, error) {
+	by, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	note := ""
+	m := string(by)
+	flag := "Imports added by gostd2joker"
+	endflag := "End gostd2joker-added imports"
+
+	if !strings.Contains(m, flag) {
+		note = "Adding custom import line to " + filepath.ToSlash(f)
+		m = strings.Replace(m, "import", "import ( // "+flag+"\n) // "+endflag+"\n\nimport", 1)
+		m = "// Auto-modified by gostd2joker at " + g.curTimeAndVersion() + "\n\n" + m
+	}
+
+	reImport := regexp.MustCompile("(?msU)" + flag + ".*" + endflag) // [^(]*[(][^)]*[)]
+	newImports := "\n"
+	importPrefix := "\t_ \"github.com/candid82/joker/std/go/"
+	for _, p := range pkgs {
+		newImports += importPrefix + p + "\"\n"
+	}
+	m = reImport.ReplaceAllString(m, flag+newImports+") // "+endflag)
+
+	return &OutputFile{Path: f, Kind: JokerFile, Contents: m, Perm: 0777, Note: note}, nil
+}
+
+// E.g.: *loaded-libs* #{'joker.core 'joker.os 'joker.base64 'joker.json 'joker.string 'joker.yaml 'joker.go.net})
+func (g *Generator) updateCoreDotJoke(pkgs []string, f string) (*OutputFile, error) {
+	by, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	note := ""
+	m := string(by)
+	flag := "Loaded-libraries added by gostd2joker"
+	endflag := "End gostd2joker-added loaded-libraries"
+
+	if !strings.Contains(m, flag) {
+		note = "Adding custom loaded libraries to " + filepath.ToSlash(f)
+		m = strings.Replace(m, "\n  *loaded-libs* #{",
+			"\n  *loaded-libs* #{\n   ;; "+flag+"\n   ;; "+endflag+"\n", 1)
+		m = ";;;; Auto-modified by gostd2joker at " + g.curTimeAndVersion() + "\n\n" + m
+	}
+
+	reImport := regexp.MustCompile("(?msU)" + flag + ".*" + endflag + "\n *?")
+	newImports := "\n  "
+	curLine := ""
+	for _, p := range pkgs {
+		more := " 'joker." + g.namespace(p)
+		if curLine != "" && len(curLine)+len(more) > 77 {
+			newImports += curLine + "\n  "
+			curLine = more
+		} else {
+			curLine += more
+		}
+	}
+	newImports += curLine
+	m = reImport.ReplaceAllString(m, flag+newImports+"\n   ;; "+endflag+"\n   ")
+
+	return &OutputFile{Path: f, Kind: JokerFile, Contents: m, Perm: 0777, Note: note}, nil
+}
+
+// E.g.: (def namespaces ['string 'json 'base64 'os 'time 'yaml 'http 'math 'html 'url])
+func (g *Generator) updateGenerateSTD(pkgs []string, f string) (*OutputFile, error) {
+	by, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	note := ""
+	m := string(by)
+	flag := "Namespaces added by gostd2joker"
+	endflag := "End gostd2joker-added namespaces"
+
+	if !strings.Contains(m, flag) {
+		note = "Adding custom namespaces to " + filepath.ToSlash(f)
+		m = strings.Replace(m, "(def namespaces [",
+			"(def namespaces [\n  ;; "+flag+"\n  ;; "+endflag+"\n  ", 1)
+		m = ";;;; Auto-modified by gostd2joker at " + g.curTimeAndVersion() + "\n\n" + m
+	}
+
+	reImport := regexp.MustCompile("(?msU)" + flag + ".*" + endflag + "\n *?")
+	newImports := "\n "
+	curLine := ""
+	for _, p := range pkgs {
+		more := " '" + g.namespace(p)
+		if curLine != "" && len(curLine)+len(more) > 77 {
+			newImports += curLine + "\n "
+			curLine = more
+		} else {
+			curLine += more
+		}
+	}
+	newImports += curLine
+	m = reImport.ReplaceAllString(m, flag+newImports+"\n  ;; "+endflag+"\n  ")
+
+	return &OutputFile{Path: f, Kind: JokerFile, Contents: m, Perm: 0777, Note: note}, nil
+}
+
+func packageImportList(pi packageImports) []string {
+	imports := []string{}
+	sortedPackageImports(pi,
+		func(k string) {
+			imports = append(imports, k)
+		})
+	return imports
+}
+
+func init() {
+	nonEmptyLineRegexp = regexp.MustCompile(`(?m)^(.)`)
+}
@@ -0,0 +1,554 @@
+package gen
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+/* A Generator holds all the state of a single run of gostd2joker, so
+/* it can be embedded in other tools (and run more than once in one
+/* process). It writes nothing itself: Run returns a model of what it
+/* found and generated, including the contents of the files to be
+/* written, leaving it to the caller (such as the gostd2joker command)
+/* to write them. */
+
+// Options control what a Generator reads and generates.
+type Options struct {
+	GoDir                 string                   // Go source tree (containing src/)
+	JokerDir              string                   // Joker source tree to generate into, or "" for a "dry run"
+	GenerateEmpty         bool                     // Generate empty packages (those with no Joker code)
+	Verbose               bool                     // Log what's going on
+	Dump                  bool                     // Log ASTs of pertinent elements (functions, types, etc.)
+	NoTimestamp           bool                     // Omit the time (and version) from generated/modified files
+	ErrorsAsExceptions    bool                     // Throw, rather than return, trailing non-nil error results
+	ErrorsAsExceptionsFor []string                 // Same, but only for these packages (net/url) and functions (net/url.Parse)
+	TypedErrors           bool                     // Convert errors to exceptions carrying the exported fields of known error types
+	RecoverPanics         bool                     // Rethrow Go panics in wrapped functions as Joker exceptions
+	PointerRefs           bool                     // Return pointer results as references to the (live) Go values
+	RoundTrip             bool                     // Keep Go values in converted maps' metadata, and accept such maps for struct params
+	RawResults            bool                     // Return results unconverted while go.gostd/*raw-results* is true
+	LazySlices            bool                     // Return slices as lazy sequences
+	MaxDepth              int                      // Return structs nested more than this (if nonzero) deep as handles
+	OutParams             []string                 // Out-params, each as "pkg.Func:p[+q]" (":" alone disables)
+	Async                 []string                 // Functions (net/http.ListenAndServe) to call in goroutines, returning futures
+	Converters            map[string]TypeConverter // Go types ("uuid.UUID") to custom converters, overriding any built-in ones
+	TemplateDir           string                   // Directory of templates (e.g. joke-func.tmpl) overriding the defaults
+	OmitUnsupported       bool                     // Leave functions that can't be generated out of the files, rather than commenting them out
+	Config                *Config                  // Per-project exclusions and overrides, if any
+	Log                   io.Writer                // Where verbose (and dry-run) output goes; nil discards it
+}
+
+type Generator struct {
+	opts Options
+	log  io.Writer
+
+	// Options, as consulted while generating code.
+	dump                  bool
+	verbose               bool
+	noTimeAndVersion      bool
+	errorsAsExceptions    bool
+	errorsAsExceptionsFor map[string]bool // Packages and qualified functions for which errors are thrown
+	typedErrors           bool
+	recoverPanics         bool
+	pointerRefs           bool
+	roundTrip             bool
+	rawResults            bool
+	lazySlices            bool
+	maxDepth              int
+	asyncFunctions        map[string]bool     // Qualified names of functions called in goroutines
+	outParams             map[string][]string // Qualified function names to their out-params
+	converters            map[string]TypeConverter
+	excludeDirs           map[string]bool // Names of directories not walked
+	templates             *template.Template
+
+	// State of a run.
+	fset                  *token.FileSet
+	methods               int
+	generatedFunctions    int
+	qualifiedFunctions    map[string]*funcInfo // Qualified function names to info on each
+	types                 map[string]*typeInfo
+	errorTypes            map[string]bool // Error types to whether only a pointer to each implements error
+	packagesInfo          map[string]*packageInfo
+	runtimeNeeded         bool                // Whether any generated code refers to the support package
+	dynamicNeeded         bool                // Whether any generated code converts Go values dynamically
+	handlesNeeded         bool                // Whether any generated code returns handles (GoObject's) to Go values
+	callbackAdapters      map[string]codeInfo // Packages to callback adapter types to their definitions
+	jokerCode             map[string]codeInfo // Packages to functions to their Joker code
+	goCode                map[string]codeInfo // Packages to functions to their Go code
+	goSupportCode         map[string]codeInfo // Packages to names to support code (following the functions)
+	genSymIndex           map[string]int
+	structDepth           int             // How many structs are being converted (to maps) at this point
+	convertersUsed        map[string]bool // Types whose custom converters the function being generated uses
+	abends                map[string]int
+	functionAbends        map[string][]string // Qualified function names to the ABENDs preventing their generation
+	diagnostics           []Diagnostic
+	decls                 []*Declaration
+	pending               []pendingAbend    // Unsupported constructs found in the function being generated
+	templateErr           error             // First error executing a template
+	dirsSeen              map[string]bool   // Relative (Unix-style) paths of directories walked
+	dirNamesSeen          map[string]bool   // Base names of all directories found (even if excluded)
+	renames               map[string]string // Qualified function names to their (validated) Joker names
+	invalidOutParams      map[string]bool   // Out-params ("pkg.Func:p") in Options.Config found to be invalid
+	typeExcluded          map[string]bool   // Qualified functions taking or returning types excluded by Options.Config
+	resultsAsMap          bool              // Whether the function being generated returns multiple results as a map
+	outRefs               bool              // Whether the function being generated returns its out-params as refs
+	fileImports           map[string]string // Package names to paths, as imported by the file declaring the function being generated
+	importsUsed           []string          // Packages to which the code being generated refers (including via custom converters)
+	errorResult           string            // The Go expression for the key (NIL for the whole result) of the error returned by the function being generated
+	currentTimeAndVersion string
+}
+
+// Result describes what a Run found and generated.
+type Result struct {
+	Packages     []*GoPackage   // In order of their paths
+	Functions    []*Function    // In order of their qualified names
+	TypeDefs     []*GoType      // Exported types, in order of their qualified names
+	Declarations []*Declaration // Exported methods, constants, and variables, in order of their qualified names
+	Diagnostics  []Diagnostic   // Notes and warnings, then Unsupported constructs (in order of their functions)
+	Files        []*OutputFile  // In the order they're to be written
+	Abends       map[string]int // ABEND codes (e.g. "042") to their numbers of occurrences
+	Types        int            // Number of (exported) types found
+	Methods      int            // Number of methods found (all skipped)
+}
+
+// A GoPackage is a Go package with at least one exported function.
+type GoPackage struct {
+	Path           string // Relative (Unix-style) path, e.g. "net/url"
+	Empty          bool   // Whether no functions were generated for it
+	HasGoFiles     bool   // Whether a *_native.go file was generated for it
+	ImportsRuntime bool   // Whether its generated Go code refers to the support package
+}
+
+// A Function is an exported, standalone function of a GoPackage.
+type Function struct {
+	Name      string   // Qualified name, e.g. "net/url.Parse"
+	JokerName string   // Qualified Joker name, e.g. "go.net.url/Parse"
+	Package   string   // Relative (Unix-style) path of its package
+	File      string   // Relative (Unix-style) path of the defining file
+	Excluded  bool     // Whether it was excluded (via Options.Config), so not generated
+	Generated bool     // Whether it was generated (else its code is commented out, or it was excluded)
+	JokerCode string   // Its definition in the .joke file
+	GoCode    string   // Its wrapper in the *_native.go file, if any
+	Abends    []string // ABEND codes (e.g. "042") explaining why it wasn't generated
+	Signature string   // Its Go signature, less param names, e.g. "func Parse(string) (*URL, error)"
+}
+
+// A GoType is an exported type defined by a Go package.
+type GoType struct {
+	Name       string // Qualified name, e.g. "net/url.URL"
+	Package    string // Relative (Unix-style) path of its package
+	File       string // Relative (Unix-style) path of the defining file
+	Kind       string // E.g. "struct", "interface", or "alias"
+	Predicate  bool   // Whether a Joker predicate (e.g. go.net.url/URL?) was generated for it
+	Definition string // E.g. "type Values map[string][]string" (less the unexported fields of a struct)
+}
+
+type DeclKind string
+
+const (
+	Method   DeclKind = "method"
+	Constant DeclKind = "constant"
+	Variable DeclKind = "variable"
+)
+
+// A Declaration is an exported method (of an exported type), constant, or
+// variable of a Go package. None are wrapped (yet).
+type Declaration struct {
+	Name      string // Qualified name, e.g. "net/url.URL.String" or "net/http.MethodGet"
+	Kind      DeclKind
+	Package   string // Relative (Unix-style) path of its package
+	File      string // Relative (Unix-style) path of the declaring file
+	Receiver  string // For a method, its receiver's (qualified) type, e.g. "net/url.URL"
+	Signature string // E.g. "func (*URL) String() string", "const MethodGet = \"GET\"", or "var ErrNoCookie = errors.New(\"http: named cookie not present\")"
+}
+
+type Severity string
+
+const (
+	Note        Severity = "note"
+	Warning     Severity = "warning"
+	Unsupported Severity = "unsupported" // A Go construct preventing a function's generation
+)
+
+// A Diagnostic is a note or warning about the input, or (if
+// Unsupported) the reason a function wasn't generated, as marked by
+// its ABEND code in the function's commented-out code.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code,omitempty"`      // ABEND code (e.g. "883"), if Unsupported
+	File     string   `json:"file,omitempty"`      // Relative (Unix-style) filename, if pertinent
+	Line     int      `json:"line,omitempty"`      // Where in File, if known
+	Column   int      `json:"column,omitempty"`    // Likewise
+	Function string   `json:"function,omitempty"`  // Qualified name (e.g. "net/url.Parse") of the function affected, if any
+	Type     string   `json:"type,omitempty"`      // Go type (e.g. "http.Handler") that isn't supported, if known
+	TypePath string   `json:"type_path,omitempty"` // Named type it's of, qualified by package path (e.g. "net/http.Handler"), if known
+	Message  string   `json:"message"`
+}
+
+type FileKind int
+
+const (
+	PackageFile FileKind = iota // A .joke or *_native.go file for a package
+	RuntimeFile                 // Part of the support package (go.gostd)
+	JokerFile                   // An existing Joker source file, updated to refer to the generated packages
+)
+
+// An OutputFile is to be written (created or replaced) by the caller.
+type OutputFile struct {
+	Path     string // Native pathname
+	Kind     FileKind
+	Contents string
+	Perm     os.FileMode // Permissions, if newly created
+	Note     string      // Describes what's being added to an existing (JokerFile) file, if anything
+}
+
+// A ParseError reports input (Go source, a Config, or templates) that
+// couldn't be read or parsed, as opposed to an invalid Option.
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// New returns a Generator for the given options.
+func New(opts Options) (*Generator, error) {
+	g := &Generator{
+		opts:                  opts,
+		log:                   opts.Log,
+		dump:                  opts.Dump,
+		verbose:               opts.Verbose,
+		noTimeAndVersion:      opts.NoTimestamp,
+		errorsAsExceptions:    opts.ErrorsAsExceptions,
+		errorsAsExceptionsFor: map[string]bool{},
+		typedErrors:           opts.TypedErrors,
+		recoverPanics:         opts.RecoverPanics,
+		pointerRefs:           opts.PointerRefs,
+		roundTrip:             opts.RoundTrip,
+		rawResults:            opts.RawResults,
+		lazySlices:            opts.LazySlices,
+		maxDepth:              opts.MaxDepth,
+		asyncFunctions:        map[string]bool{},
+		outParams:             map[string][]string{},
+		converters:            map[string]TypeConverter{},
+		excludeDirs:           map[string]bool{},
+	}
+	if g.log == nil {
+		g.log = ioutil.Discard
+	}
+	if opts.GoDir == "" {
+		return nil, fmt.Errorf("no Go source directory specified")
+	}
+	if opts.MaxDepth < 0 {
+		return nil, fmt.Errorf("invalid max depth %d", opts.MaxDepth)
+	}
+	for _, p := range opts.ErrorsAsExceptionsFor {
+		g.errorsAsExceptionsFor[p] = true
+	}
+	for _, p := range opts.Async {
+		g.asyncFunctions[p] = true
+	}
+	for k, c := range opts.Converters {
+		g.converters[k] = c
+	}
+	t, err := parseTemplates(opts.TemplateDir)
+	if err != nil {
+		return nil, &ParseError{err}
+	}
+	g.templates = t
+	for k, v := range excludeDirs {
+		g.excludeDirs[k] = v
+	}
+	for k, v := range defaultOutParams {
+		g.outParams[k] = v
+	}
+	if opts.Config != nil {
+		g.applyConfig(opts.Config)
+	}
+	for _, p := range opts.OutParams {
+		if e := g.addOutParams(p); e != nil {
+			return nil, e
+		}
+	}
+	return g, nil
+}
+
+func (g *Generator) reset() {
+	g.fset = token.NewFileSet() // positions are relative to fset
+	g.methods = 0
+	g.generatedFunctions = 0
+	g.qualifiedFunctions = map[string]*funcInfo{}
+	g.types = map[string]*typeInfo{}
+	g.errorTypes = map[string]bool{}
+	g.packagesInfo = map[string]*packageInfo{}
+	g.runtimeNeeded = false
+	g.dynamicNeeded = false
+	g.handlesNeeded = false
+	g.callbackAdapters = map[string]codeInfo{}
+	g.jokerCode = map[string]codeInfo{}
+	g.goCode = map[string]codeInfo{}
+	g.goSupportCode = map[string]codeInfo{}
+	g.genSymIndex = map[string]int{}
+	g.structDepth = 0
+	g.abends = map[string]int{}
+	g.functionAbends = map[string][]string{}
+	g.diagnostics = nil
+	g.decls = nil
+	g.pending = nil
+	g.templateErr = nil
+	g.dirsSeen = map[string]bool{}
+	g.dirNamesSeen = map[string]bool{}
+	g.renames = map[string]string{}
+	g.invalidOutParams = map[string]bool{}
+	g.typeExcluded = map[string]bool{}
+}
+
+func (g *Generator) logf(format string, args ...interface{}) {
+	fmt.Fprintf(g.log, format, args...)
+}
+
+func (g *Generator) diagnose(sev Severity, file, msg string) {
+	g.diagnostics = append(g.diagnostics, Diagnostic{Severity: sev, File: file, Message: msg})
+}
+
+// Whether the (commented-out) code for the named function of the
+// package is to be left out of the generated files.
+func (g *Generator) omitted(pkgDirUnix, name string) bool {
+	return g.opts.OmitUnsupported && g.functionAbends[pkgDirUnix+"."+name] != nil
+}
+
+// Run reads the Go source tree and generates the Joker (and Go) code
+// wrapping it, returning the results.
+func (g *Generator) Run() (*Result, error) {
+	g.reset()
+
+	sourceDir := filepath.Join(g.opts.GoDir, "src")
+	if fi, e := os.Stat(filepath.Join(sourceDir, "go")); e != nil || !fi.IsDir() {
+		if m, e := filepath.Glob(filepath.Join(sourceDir, "*.go")); e != nil || m == nil || len(m) == 0 {
+			return nil, &ParseError{fmt.Errorf("Does not exist or is not a Go source directory: %s;\n%v", sourceDir, m)}
+		}
+	}
+
+	jokerLibDir := ""
+	if g.opts.JokerDir != "" {
+		jokerLibDir = filepath.Join(g.opts.JokerDir, "std", "go")
+	}
+
+	err := g.walkDirs(filepath.Join(sourceDir, "."), parser.ParseComments)
+	if err != nil {
+		return nil, &ParseError{fmt.Errorf("Error walking directory %s: %v", sourceDir, err)}
+	}
+
+	g.validateConfig()
+
+	sort.SliceStable(g.diagnostics, func(i, j int) bool { return g.diagnostics[i].Message < g.diagnostics[j].Message })
+
+	if g.verbose {
+		/* Output map in sorted order to stabilize for testing. */
+		sortedTypeInfoMap(g.types,
+			func(t string, ti *typeInfo) {
+				g.logf("TYPE %s:\n", t)
+				g.logf("  %s\n", ti.file)
+			})
+	}
+
+	/* Generate function code snippets in alphabetical order, to stabilize test output in re unsupported types. */
+	sortedFuncInfoMap(g.qualifiedFunctions,
+		func(f string, v *funcInfo) {
+			if err == nil && g.functionWanted(f) {
+				err = g.genFunction(f, v)
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	sortedPackagesInfo(g.packagesInfo,
+		func(p string, i *packageInfo) {
+			g.genSupportCode(p)
+		})
+
+	res := &Result{Abends: g.abends, Types: len(g.types), Methods: g.methods}
+
+	sortedPackageMap(g.jokerCode,
+		func(pkgDirUnix string, v codeInfo) {
+			pkgBaseName := path.Base(pkgDirUnix)
+			var out *strings.Builder
+			if jokerLibDir != "" && (g.opts.GenerateEmpty || g.packagesInfo[pkgDirUnix].nonEmpty) {
+				out = &strings.Builder{}
+
+				pi := g.packagesInfo[pkgDirUnix]
+
+				out.WriteString(g.execute(jokeHeaderTemplate, &JokeHeader{
+					Path:      pkgDirUnix,
+					Namespace: g.namespace(pkgDirUnix),
+					Imports:   packageImportList(pi.importsAutoGen),
+					Empty:     !pi.nonEmpty,
+					Generated: g.curTimeAndVersion(),
+				}))
+			}
+			sortedCodeMap(v,
+				func(f string, w string) {
+					if g.verbose || jokerLibDir == "" {
+						g.logf("JOKER FUNC %s.%s has:%v\n",
+							pkgBaseName, f, w)
+					}
+					if out != nil && !g.omitted(pkgDirUnix, f) {
+						out.WriteString(w)
+					}
+				})
+			if out != nil {
+				jf := filepath.Join(jokerLibDir, filepath.FromSlash(pkgDirUnix)+".joke")
+				res.Files = append(res.Files, &OutputFile{Path: jf, Kind: PackageFile, Contents: out.String(), Perm: 0666})
+			}
+		})
+	sortedPackageMap(g.goCode,
+		func(pkgDirUnix string, v codeInfo) {
+			pkgBaseName := path.Base(pkgDirUnix)
+			pi := g.packagesInfo[pkgDirUnix]
+			g.packagesInfo[pkgDirUnix].hasGoFiles = true
+			pkgDirNative := filepath.FromSlash(pkgDirUnix)
+
+			var out *strings.Builder
+			if jokerLibDir != "" && (g.opts.GenerateEmpty || g.packagesInfo[pkgDirUnix].nonEmpty) {
+				out = &strings.Builder{}
+
+				imports := []GoImport{}
+				for _, p := range packageImportList(pi.importsNative) {
+					imports = append(imports, GoImport{Name: "_" + path.Base(p), Path: p})
+				}
+				if _, f := pi.importsNative[pkgDirUnix]; f {
+					imports = append(imports, GoImport{Name: ".", Path: "github.com/candid82/joker/core"})
+				}
+				if pi.importsRuntime {
+					imports = append(imports, GoImport{Name: runtimePkg, Path: runtimeImport})
+				}
+
+				out.WriteString(g.execute(goHeaderTemplate, &GoHeader{
+					Path:      pkgDirUnix,
+					Name:      pkgBaseName,
+					Imports:   imports,
+					Generated: g.curTimeAndVersion(),
+				}))
+			}
+			sortedCodeMap(v,
+				func(f string, w string) {
+					if g.verbose || jokerLibDir == "" {
+						g.logf("GO FUNC %s.%s has:%v\n",
+							pkgBaseName, f, w)
+					}
+					if out != nil && !g.omitted(pkgDirUnix, f) {
+						out.WriteString(w)
+					}
+				})
+			sortedCodeMap(g.goSupportCode[pkgDirUnix],
+				func(f string, w string) {
+					if g.verbose || jokerLibDir == "" {
+						g.logf("GO SUPPORT %s.%s has:%v\n",
+							pkgBaseName, f, w)
+					}
+					if out != nil {
+						out.WriteString(w)
+					}
+				})
+			if out != nil {
+				gf := filepath.Join(jokerLibDir, pkgDirNative, pkgBaseName+"_native.go")
+				res.Files = append(res.Files, &OutputFile{Path: gf, Kind: PackageFile, Contents: out.String(), Perm: 0666})
+			}
+		})
+
+	if g.templateErr != nil {
+		return nil, g.templateErr
+	}
+
+	if jokerLibDir != "" && g.runtimeNeeded {
+		res.Files = append(res.Files, g.genRuntime(jokerLibDir)...)
+	}
+
+	if g.opts.JokerDir != "" {
+		var packagesArray = []string{} // Relative package pathnames in alphabetical order
+		var dotJokeArray = []string{}  // Relative package pathnames in alphabetical order
+
+		sortedPackagesInfo(g.packagesInfo,
+			func(p string, i *packageInfo) {
+				if !g.opts.GenerateEmpty && !i.nonEmpty {
+					return
+				}
+				if i.hasGoFiles {
+					packagesArray = append(packagesArray, p)
+				}
+				dotJokeArray = append(dotJokeArray, p)
+			})
+		if g.runtimeNeeded {
+			packagesArray = append(packagesArray, runtimePkg)
+			dotJokeArray = append(dotJokeArray, runtimePkg)
+			sort.Strings(packagesArray)
+			sort.Strings(dotJokeArray)
+		}
+		mainFile, err := g.updateJokerMain(packagesArray, filepath.Join(g.opts.JokerDir, "main.go"))
+		if err != nil {
+			return nil, err
+		}
+		coreFile, err := g.updateCoreDotJoke(dotJokeArray, filepath.Join(g.opts.JokerDir, "core", "data", "core.joke"))
+		if err != nil {
+			return nil, err
+		}
+		stdFile, err := g.updateGenerateSTD(packagesArray, filepath.Join(g.opts.JokerDir, "std", "generate-std.joke"))
+		if err != nil {
+			return nil, err
+		}
+		res.Files = append(res.Files, mainFile, coreFile, stdFile)
+	}
+
+	sortedPackagesInfo(g.packagesInfo,
+		func(p string, i *packageInfo) {
+			res.Packages = append(res.Packages, &GoPackage{Path: p, Empty: !i.nonEmpty, HasGoFiles: i.hasGoFiles, ImportsRuntime: i.importsRuntime})
+		})
+	sortedFuncInfoMap(g.qualifiedFunctions,
+		func(f string, v *funcInfo) {
+			name := strings.TrimPrefix(f, v.pkgDirUnix+".")
+			res.Functions = append(res.Functions, &Function{
+				Name:      f,
+				JokerName: g.namespace(v.pkgDirUnix) + "/" + g.jokerName(f, v),
+				Package:   v.pkgDirUnix,
+				File:      v.filename,
+				Excluded:  !g.functionWanted(f),
+				Generated: g.functionWanted(f) && g.functionAbends[f] == nil,
+				JokerCode: g.jokerCode[v.pkgDirUnix][name],
+				GoCode:    g.goCode[v.pkgDirUnix][name],
+				Abends:    g.functionAbends[f],
+				Signature: goSignature(v.fd),
+			})
+		})
+	sortedTypeInfoMap(g.types,
+		func(t string, ti *typeInfo) {
+			pkgDirUnix := t[:strings.LastIndex(t, ".")]
+			res.TypeDefs = append(res.TypeDefs, &GoType{
+				Name:       t,
+				Package:    pkgDirUnix,
+				File:       ti.file,
+				Kind:       typeKind(ti.td),
+				Predicate:  g.jokerCode[pkgDirUnix][ti.td.Name.Name+"?"] != "",
+				Definition: typeDefinition(ti.td),
+			})
+		})
+	sort.SliceStable(g.decls, func(i, j int) bool { return g.decls[i].Name < g.decls[j].Name })
+	res.Declarations = g.decls
+	res.Diagnostics = g.diagnostics
+
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package gen
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunErrors(t *testing.T) {
+	jokerDir, err := ioutil.TempDir("", "gostd2joker-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(jokerDir) // Lacking main.go and the rest of a Joker tree
+
+	goDir := filepath.Join("..", "tests", "small")
+	for _, tc := range []struct {
+		name       string
+		opts       Options
+		parseError bool
+	}{
+		{"no Go tree", Options{GoDir: filepath.Join(jokerDir, "nonexistent")}, true},
+		{"bad Joker tree", Options{GoDir: goDir, JokerDir: jokerDir}, false},
+		{"missing Joker tree", Options{GoDir: goDir, JokerDir: filepath.Join(jokerDir, "nonexistent")}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := New(tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := g.Run()
+			if err == nil {
+				t.Fatalf("got %d files, not an error", len(res.Files))
+			}
+			var pe *ParseError
+			if errors.As(err, &pe) != tc.parseError {
+				t.Errorf("got %v, a ParseError: %v", err, !tc.parseError)
+			}
+		})
+	}
+}
+
+func TestRunDuplicateType(t *testing.T) {
+	goDir, err := ioutil.TempDir("", "gostd2joker-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(goDir)
+	pkgDir := filepath.Join(goDir, "src", "dup")
+	for _, d := range []string{pkgDir, filepath.Join(goDir, "src", "go")} {
+		if err := os.MkdirAll(d, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code := "package dup\n\ntype T int\n\ntype T string\n\nfunc F() T { return 0 }\n"
+	if err := ioutil.WriteFile(filepath.Join(pkgDir, "dup.go"), []byte(code), 0666); err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(Options{GoDir: goDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.Run()
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Errorf("got %v, not a ParseError", err)
+	}
+}
+
+func TestErrorDataWithoutFields(t *testing.T) {
+	goDir, err := ioutil.TempDir("", "gostd2joker-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(goDir)
+	pkgDir := filepath.Join(goDir, "src", "errs")
+	for _, d := range []string{pkgDir, filepath.Join(goDir, "src", "go")} {
+		if err := os.MkdirAll(d, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	code := "package errs\n\ntype Error string\n\nfunc (e Error) Error() string { return string(e) }\n\nfunc F() error { return nil }\n"
+	if err := ioutil.WriteFile(filepath.Join(pkgDir, "errs.go"), []byte(code), 0666); err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(Options{GoDir: goDir, JokerDir: filepath.Join("..", "tests", "joker.orig"), TypedErrors: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range res.Files {
+		if filepath.Base(f.Path) == "errs_native.go" {
+			if !strings.Contains(f.Contents, "\tswitch err.(type) {\n\tcase _errs.Error:\n") {
+				t.Errorf("errorData declares an unused _e:\n%s", f.Contents)
+			}
+			return
+		}
+	}
+	t.Error("no errs_native.go generated")
+}
@@ -0,0 +1,1690 @@
+package gen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+/* Code shared by the generated *_native.go files lives in a small
+/* support package, written (alongside those files) to
+/* <joker-std-subdir>/gostd/ whenever any generated code refers to
+/* it. Each entry below maps a filename within that package to its
+/* contents, sans the "Auto-generated" header. */
+
+// Name of the support package.
+const runtimePkg = "gostd"
+
+const runtimeImport = "github.com/candid82/joker/std/go/" + runtimePkg
+
+// Generated code refers to support routines via this prefix.
+const runtimePrefix = runtimePkg + "."
+
+var runtimeFiles = codeInfo{
+	"callbacks.go": runtimeCallbacks,
+	"context.go":   runtimeContext,
+	"convert.go":   runtimeConvert,
+	"errors.go":    runtimeErrors,
+	"futures.go":   runtimeFutures,
+	"lazy.go":      runtimeLazy,
+	"objects.go":   runtimeObjects,
+	"panics.go":    runtimePanics,
+	"raw.go":       runtimeRaw,
+	"refs.go":      runtimeRefs,
+	"roundtrip.go": runtimeRoundTrip,
+	"streams.go":   runtimeStreams,
+	"types.go":     runtimeTypes,
+}
+
+// The go.gostd namespace, which exposes some of the support package to
+// Joker code.
+var runtimeJoke = `
+(ns
+  ^{:go-imports []
+    :doc "Provides support for the go.* namespaces generated by gostd2joker."}
+  go.gostd)
+
+(defn reset
+  "Sets the Go value referred to by ref, as returned by a generated\nfunction, to newval. Returns newval."
+  {:added "1.0"
+   :go "reset(_ref, _newval)"}
+  [^Object _ref ^Object _newval])
+
+(defn read-all
+  "Reads all remaining text from rdr (a Go reader, String, File, or *in*),\nlike slurp."
+  {:added "1.0"
+   :go "readAll(_rdr)"}
+  [^Object _rdr])
+
+(defn read-lines
+  "Returns a vector of the lines of text remaining in rdr (a Go reader,\nString, File, or *in*), like line-seq."
+  {:added "1.0"
+   :go "readLines(_rdr)"}
+  [^Object _rdr])
+
+(defn write
+  "Writes the string s to w (a Go writer, File, or *out*), flushing it\nif it is buffered. Returns the number of bytes written."
+  {:added "1.0"
+   :go "write(_w, _s)"}
+  [^Object _w ^Object _s])
+
+(defn context
+  "Returns a new Go context, for passing to generated functions that take\none. It is cancelled by cancel, by SIGINT while a function it was\npassed to runs, or, if specified, after timeout milliseconds."
+  {:added "1.0"
+   :go {0 "newContext(NIL)"
+        1 "newContext(_timeout)"}}
+  ([])
+  ([^Object _timeout]))
+
+(defn cancel
+  "Cancels ctx, as returned by context."
+  {:added "1.0"
+   :go "cancelContext(_ctx)"}
+  [^Object _ctx])
+
+(defn await
+  "Waits for, then returns, the result of fut, a GoFuture returned by a\nfunction generated with --async, throwing any exception thrown by the\ncall. If timeout (in milliseconds) elapses first, returns timeout-val."
+  {:added "1.0"
+   :go {1 "await(_fut, NIL, NIL)"
+        3 "await(_fut, _timeout, _timeout_val)"}}
+  ([^Object _fut])
+  ([^Object _fut ^Object _timeout ^Object _timeout_val]))
+
+(defn done?
+  "Returns whether the call underlying fut, a GoFuture, has completed."
+  {:added "1.0"
+   :go "futureDone(_fut)"}
+  [^Object _fut])
+
+(defn error
+  "Waits for the call underlying fut, a GoFuture, to complete, returning\nthe exception it threw or the error it returned, else nil."
+  {:added "1.0"
+   :go "futureError(_fut)"}
+  [^Object _fut])
+
+(defn set-callback-queue!
+  "Limits the number of Joker fns, called back by Go code, that may wait\nto run (callbacks run one at a time, and only while the Joker thread\nwaits on Go code), and how long (in milliseconds) each may wait. Zero\nmeans no limit. Go callers that exceed either limit panic."
+  {:added "1.0"
+   :go "setCallbackQueue(_max, _timeout)"}
+  [^Object _max ^Object _timeout])
+
+(def ^:dynamic *raw-results*
+  "When true, functions generated with --raw-results return their results\nas Go values (wrapped by GoObjects), unconverted."
+  false)
+
+(defmacro raw
+  "Evaluates body with *raw-results* bound to true, so that calls it makes\nto generated functions return Go values instead of Joker data."
+  {:added "1.0"}
+  [& body]
+  (list* 'binding '[go.gostd/*raw-results* true] body))
+
+(defn go-type
+  "Returns the package-qualified name (e.g. \"net/url.URL\") of the Go type\nof x, a Go value (wrapped by a GoObject) or a map converted from one;\nelse nil."
+  {:added "1.0"
+   :go "goType(_x)"}
+  [^Object _x])
+`
+
+var runtimeErrors = `package gostd
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	. "github.com/candid82/joker/core"
+)
+
+// An ErrorDataFn adds the exported fields of err to data, returning
+// whether err is of a type it knows about.
+type ErrorDataFn func(err error, data *ArrayMap) bool
+
+var errorDataFns []ErrorDataFn
+
+// RegisterErrorData is called (by generated code) for each package
+// defining error types.
+func RegisterErrorData(fn ErrorDataFn) {
+	errorDataFns = append(errorDataFns, fn)
+}
+
+// ThrowError panics with a Joker exception describing err, a non-nil
+// error returned by the Go function fn (e.g. "net/url.Parse").
+func ThrowError(fn string, err error) {
+	panic(makeGoException(fn, err))
+}
+
+// MakeGoError returns a Joker exception describing err, without
+// throwing it.
+func MakeGoError(err error) Object {
+	return makeGoException("", err)
+}
+
+// ThrowErrorAsync and MakeGoErrorAsync are ThrowError and MakeGoError
+// for code run by Async, off the Joker thread, so wait their turn to
+// run Joker code (ex-info).
+
+func ThrowErrorAsync(fn string, err error) {
+	panic(withEval(func() Object { return makeGoException(fn, err) }))
+}
+
+func MakeGoErrorAsync(err error) Object {
+	return withEval(func() Object { return MakeGoError(err) })
+}
+
+func makeGoException(fn string, err error) *ExInfo {
+	ex := MakeGoException(err.Error(), ErrorData(fn, err))
+	if u := errors.Unwrap(err); u != nil {
+		ex.Add(MakeKeyword("cause"), makeGoException("", u))
+	}
+	return ex
+}
+
+// ErrorData returns a map describing err: its Go type, its message,
+// the exported fields of known error types, and (as :unwrap) the
+// chain of errors it wraps, innermost last.
+func ErrorData(fn string, err error) *ArrayMap {
+	data := errorInfo(err)
+	if fn != "" {
+		data.Add(MakeKeyword("go-function"), MakeString(fn))
+	}
+	chain := EmptyVector
+	for e := errors.Unwrap(err); e != nil; e = errors.Unwrap(e) {
+		chain = chain.Conjoin(errorInfo(e))
+	}
+	data.Add(MakeKeyword("unwrap"), chain)
+	return data
+}
+
+func errorInfo(err error) *ArrayMap {
+	info := EmptyArrayMap()
+	info.Add(MakeKeyword("go-type"), MakeString(fmt.Sprintf("%T", err)))
+	info.Add(MakeKeyword("message"), MakeString(err.Error()))
+	for _, fn := range errorDataFns {
+		if fn(err, info) {
+			break
+		}
+	}
+	return info
+}
+
+var (
+	exInfoOnce sync.Once
+	exInfoVar  *Var
+)
+
+// MakeGoException returns a Joker exception with the given message
+// and data, as made by ex-info (so ex-message, ex-data, and printing
+// work on it as on any other).
+func MakeGoException(msg string, data *ArrayMap) *ExInfo {
+	exInfoOnce.Do(func() {
+		exInfoVar = GLOBAL_ENV.FindNamespace(MakeSymbol("joker.core")).Resolve("ex-info")
+	})
+	return exInfoVar.Resolve().(Callable).Call([]Object{MakeString(msg), data}).(*ExInfo)
+}
+`
+
+var runtimeConvert = `package gostd
+
+import (
+	"reflect"
+
+	. "github.com/candid82/joker/core"
+)
+
+var converters = map[reflect.Type]func(interface{}) Object{}
+
+// RegisterConverter is called (by generated code) for each type that
+// has a generated conversion to a Joker object.
+func RegisterConverter(t reflect.Type, fn func(interface{}) Object) {
+	converters[t] = fn
+}
+
+// ToGo converts a Joker object to a "natural" Go value, as passed to
+// an interface{} parameter: maps (with string keys, if they're all
+// strings or keywords), slices, strings, numbers, and so on; a
+// handle passes its Go value.
+func ToGo(o Object) interface{} {
+	switch v := o.(type) {
+	case Nil:
+		return nil
+	case String:
+		return v.S
+	case Int:
+		return v.I
+	case Double:
+		return v.D
+	case Bool:
+		return v.B
+	case Char:
+		return v.Ch
+	case Keyword:
+		return v.Name()
+	case Symbol:
+		return v.Name()
+	case *GoObject:
+		return v.O
+	case Map:
+		return mapToGo(v)
+	case Seqable:
+		res := []interface{}{}
+		for s := v.Seq(); !s.IsEmpty(); s = s.Rest() {
+			res = append(res, ToGo(s.First()))
+		}
+		return res
+	}
+	return o
+}
+
+// BytesOf converts a Joker object (a String, a seqable of Ints, or a
+// handle to a []byte) to a []byte.
+func BytesOf(o Object) []byte {
+	switch v := o.(type) {
+	case String:
+		return []byte(v.S)
+	case *GoObject:
+		if b, ok := v.O.([]byte); ok {
+			return b
+		}
+	case Seqable:
+		res := []byte{}
+		for s := v.Seq(); !s.IsEmpty(); s = s.Rest() {
+			res = append(res, byte(IntOf(s.First())))
+		}
+		return res
+	}
+	panic(RT.NewError("Expected String, seq of Ints, or []byte, got " + o.ToString(true)))
+}
+
+// NewOut returns a pointer to a new Go value, for passing as an
+// interface{} out-parameter: a copy of the Go value to which o
+// converts (or refers, if a handle), or (given nil) an interface{}.
+func NewOut(o Object) interface{} {
+	x := ToGo(o)
+	if x == nil {
+		return new(interface{})
+	}
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p.Interface()
+}
+
+// NewOuts returns pointers to new Go values, per NewOut, for the
+// elements of o (a seqable, or nil), for passing as a ...interface{}
+// out-parameter.
+func NewOuts(o Object) []interface{} {
+	res := []interface{}{}
+	if s, ok := o.(Seqable); ok {
+		for s := s.Seq(); !s.IsEmpty(); s = s.Rest() {
+			res = append(res, NewOut(s.First()))
+		}
+	}
+	return res
+}
+
+// FromOut converts the final value pointed to by p, as returned by
+// NewOut, to a Joker object.
+func FromOut(p interface{}) Object {
+	return FromGo(reflect.ValueOf(p).Elem().Interface())
+}
+
+// FromOuts converts the final values pointed to by ps, as returned by
+// NewOuts, to a vector.
+func FromOuts(ps []interface{}) Object {
+	res := EmptyVector
+	for _, p := range ps {
+		res = res.Conjoin(FromOut(p))
+	}
+	return res
+}
+
+// ToGoArgs converts a Joker seqable (or nil) to the arguments passed
+// to a ...interface{} parameter.
+func ToGoArgs(o Object) []interface{} {
+	res := []interface{}{}
+	if s, ok := o.(Seqable); ok {
+		for s := s.Seq(); !s.IsEmpty(); s = s.Rest() {
+			res = append(res, ToGo(s.First()))
+		}
+	}
+	return res
+}
+
+func mapToGo(m Map) interface{} {
+	keys := []interface{}{}
+	vals := []interface{}{}
+	stringKeys := true
+	for iter := m.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		k := ToGo(p.Key)
+		if _, ok := k.(string); !ok {
+			stringKeys = false
+		}
+		keys = append(keys, k)
+		vals = append(vals, ToGo(p.Value))
+	}
+	if stringKeys {
+		res := map[string]interface{}{}
+		for i, k := range keys {
+			res[k.(string)] = vals[i]
+		}
+		return res
+	}
+	res := map[interface{}]interface{}{}
+	for i, k := range keys {
+		res[k] = vals[i]
+	}
+	return res
+}
+
+// FromGo converts an arbitrary Go value, as returned via an
+// interface{} result, to a Joker object. Values of types with
+// registered conversions use them; others are converted via
+// reflection, with any pointer (or map) that refers back to itself
+// returned as a handle where it recurs.
+func FromGo(v interface{}) Object {
+	if v == nil {
+		return NIL
+	}
+	switch v := v.(type) {
+	case Object:
+		return v
+	case error:
+		return MakeGoError(v)
+	}
+	return fromGo(reflect.ValueOf(v))
+}
+
+func fromGo(v reflect.Value) Object {
+	return fromGoVisiting(v, map[visit]bool{})
+}
+
+// A pointer (or map) being converted, so seen again only via a cycle
+// (e.g. an *http.Request's Response.Request).
+type visit struct {
+	p uintptr
+	t reflect.Type
+}
+
+// Converts v, returning a handle (GoObject) to any pointer or map
+// reached again while converting what it refers to.
+func fromGoVisiting(v reflect.Value, visiting map[visit]bool) Object {
+	if fn, ok := converters[v.Type()]; ok {
+		return fn(v.Interface())
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		return NIL
+	case reflect.String:
+		return MakeString(v.String())
+	case reflect.Bool:
+		return MakeBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return MakeInt(int(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return MakeInt(int(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return MakeDouble(v.Float())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return NIL
+		}
+		if v.Kind() == reflect.Ptr {
+			vk := visit{v.Pointer(), v.Type()}
+			if visiting[vk] {
+				return MakeGoObject(v.Interface())
+			}
+			visiting[vk] = true
+			defer delete(visiting, vk)
+		}
+		return fromGoVisiting(v.Elem(), visiting)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return NIL
+		}
+		res := EmptyVector
+		for i := 0; i < v.Len(); i++ {
+			res = res.Conjoin(fromGoVisiting(v.Index(i), visiting))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return NIL
+		}
+		vk := visit{v.Pointer(), v.Type()}
+		if visiting[vk] {
+			return MakeGoObject(v.Interface())
+		}
+		visiting[vk] = true
+		defer delete(visiting, vk)
+		res := EmptyArrayMap()
+		for _, k := range v.MapKeys() {
+			res.Add(fromGoVisiting(k, visiting), fromGoVisiting(v.MapIndex(k), visiting))
+		}
+		return res
+	case reflect.Struct:
+		res := EmptyArrayMap()
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" { // Exported
+				res.Add(MakeKeyword(f.Name), fromGoVisiting(v.Field(i), visiting))
+			}
+		}
+		return TagGoType(res, typeName(t))
+	}
+	return MakeString(v.String()) // E.g. "<func() Value>"
+}
+`
+
+var runtimePanics = `package gostd
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	. "github.com/candid82/joker/core"
+)
+
+// Maximum number of Go stack frames included in a converted panic.
+var MaxStackFrames = 16
+
+// RecoverPanic is deferred by each generated wrapper for the Go
+// function fn (e.g. "regexp.MustCompile"). It rethrows a Go panic as
+// a Joker exception carrying the panic value, fn, and a trimmed Go
+// stack trace. Joker exceptions (e.g. thrown by ThrowError) pass
+// through unchanged.
+func RecoverPanic(fn string) {
+	if r := recover(); r != nil {
+		if _, ok := r.(Error); ok {
+			panic(r)
+		}
+		panic(panicException(fn, r))
+	}
+}
+
+// Returns a Joker exception describing r, a Go panic (still in
+// progress, so on the stack) in fn.
+func panicException(fn string, r interface{}) Error {
+	data := EmptyArrayMap()
+	data.Add(MakeKeyword("go-function"), MakeString(fn))
+	data.Add(MakeKeyword("go-type"), MakeString(fmt.Sprintf("%T", r)))
+	if err, ok := r.(error); ok {
+		data.Add(MakeKeyword("go-panic"), MakeGoError(err))
+	} else {
+		data.Add(MakeKeyword("go-panic"), MakeString(fmt.Sprintf("%v", r)))
+	}
+	data.Add(MakeKeyword("go-stack"), MakeString(trimmedStack(debug.Stack())))
+	return MakeGoException(fmt.Sprintf("Go panic in %s: %v", fn, r), data)
+}
+
+// Returns the frames, in the given "goroutine N [running]:" stack
+// trace, between the call to panic() and the Joker evaluator.
+func trimmedStack(stack []byte) string {
+	lines := strings.Split(strings.TrimRight(string(stack), "\n"), "\n")
+	start := 1 // Skip "goroutine N [running]:"
+	for i := start; i < len(lines); i += 2 {
+		if strings.HasPrefix(lines[i], "panic(") {
+			start = i + 2
+			break
+		}
+	}
+	frames := []string{}
+	for i := start; i+1 < len(lines) && len(frames) < MaxStackFrames; i += 2 {
+		if strings.HasPrefix(lines[i], "github.com/candid82/joker/core.") {
+			break
+		}
+		frames = append(frames, lines[i]+"\n"+lines[i+1])
+	}
+	return strings.Join(frames, "\n")
+}
+`
+
+var runtimeRefs = `package gostd
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	. "github.com/candid82/joker/core"
+)
+
+// A Ref refers to a Go value, typically one pointed to by a result
+// of a Go function (e.g. flag.String). Deref'ing it yields the
+// value's current contents, converted to a Joker object; resetting
+// it converts a Joker object and stores it in the value.
+type Ref struct {
+	get  func() Object
+	set  func(Object) // nil if read-only
+	info *ObjectInfo
+}
+
+var refType = RegRefType("GoRef", (*Ref)(nil), "Refers to a Go value.")
+
+func MakeRef(get func() Object, set func(Object)) *Ref {
+	return &Ref{get: get, set: set}
+}
+
+func (r *Ref) Deref() Object {
+	return r.get()
+}
+
+func (r *Ref) Reset(v Object) Object {
+	if r.set == nil {
+		panic(RT.NewError("Go reference is read-only: " + r.ToString(false)))
+	}
+	r.set(v)
+	return v
+}
+
+func (r *Ref) ToString(escape bool) string {
+	return "#object[GoRef " + r.get().ToString(escape) + "]"
+}
+
+func (r *Ref) Equals(other interface{}) bool {
+	return r == other
+}
+
+func (r *Ref) GetInfo() *ObjectInfo {
+	return r.info
+}
+
+func (r *Ref) WithInfo(info *ObjectInfo) Object {
+	res := *r
+	res.info = info
+	return &res
+}
+
+func (r *Ref) GetType() *Type {
+	return refType
+}
+
+func (r *Ref) Hash() uint32 {
+	return hashString(fmt.Sprintf("%p", r))
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+func reset(ref Object, newval Object) Object {
+	r, ok := ref.(*Ref)
+	if !ok {
+		panic(RT.NewArgTypeError(0, ref, "GoRef"))
+	}
+	return r.Reset(newval)
+}
+
+// StringOf etc. convert Joker objects to Go values, throwing if the
+// object is of the wrong type.
+
+func StringOf(o Object) string {
+	if v, ok := o.(String); ok {
+		return v.S
+	}
+	panic(RT.NewError("Expected String, got " + o.ToString(true)))
+}
+
+func BoolOf(o Object) bool {
+	if v, ok := o.(Bool); ok {
+		return v.B
+	}
+	panic(RT.NewError("Expected Bool, got " + o.ToString(true)))
+}
+
+func IntOf(o Object) int {
+	if v, ok := o.(Int); ok {
+		return v.I
+	}
+	panic(RT.NewError("Expected Int, got " + o.ToString(true)))
+}
+`
+
+var runtimeObjects = `package gostd
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	. "github.com/candid82/joker/core"
+)
+
+// A GoObject is an opaque handle to a Go value, such as an io.Reader
+// returned by a generated function, that has no natural Joker
+// representation. Keywords look up the exported fields of a struct
+// (or pointer to one); = follows Go's ==.
+type GoObject struct {
+	O    interface{}
+	info *ObjectInfo
+}
+
+var goObjectType = RegRefType("GoObject", (*GoObject)(nil), "Wraps a Go value.")
+
+// MakeGoObject returns a handle to o, or nil if o is nil or a nil
+// pointer.
+func MakeGoObject(o interface{}) Object {
+	if o == nil {
+		return NIL
+	}
+	if v := reflect.ValueOf(o); v.Kind() == reflect.Ptr && v.IsNil() {
+		return NIL
+	}
+	return &GoObject{O: o}
+}
+
+// A Getter returns the Joker object for the named exported field of
+// a struct (passed by pointer), and whether there is such a field.
+type Getter func(o interface{}, field string) (bool, Object)
+
+var getters = map[reflect.Type]Getter{}
+
+// RegisterGetter is called (by generated code) for each struct type
+// with exported fields, given the type of pointers to it.
+func RegisterGetter(t reflect.Type, fn Getter) {
+	getters[t] = fn
+}
+
+func (o *GoObject) Get(key Object) (bool, Object) {
+	k, ok := key.(Keyword)
+	if !ok {
+		return false, nil
+	}
+	v := reflect.ValueOf(o.O)
+	if v.Kind() != reflect.Ptr {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p
+	}
+	for v.Elem().Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.IsNil() {
+		return false, nil
+	}
+	if fn, ok := getters[v.Type()]; ok {
+		return fn(v.Interface(), k.Name())
+	}
+	if e := v.Elem(); e.Kind() == reflect.Struct {
+		if f, ok := e.Type().FieldByName(k.Name()); ok && f.PkgPath == "" {
+			return true, fromGo(e.FieldByIndex(f.Index))
+		}
+	}
+	return false, nil
+}
+
+func (o *GoObject) ToString(escape bool) string {
+	if v := reflect.ValueOf(o.O); v.Kind() == reflect.Ptr && v.IsNil() {
+		return "<nil>" // Its String method might not expect a nil receiver
+	}
+	if s, ok := o.O.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return "#object[GoObject " + typeName(reflect.TypeOf(o.O)) + "]"
+}
+
+func (o *GoObject) Equals(other interface{}) (res bool) {
+	p, ok := other.(*GoObject)
+	if !ok {
+		return false
+	}
+	if o == p {
+		return true
+	}
+	t := reflect.TypeOf(o.O)
+	if t != reflect.TypeOf(p.O) || !t.Comparable() {
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			res = false // E.g. interface fields holding uncomparable values
+		}
+	}()
+	return o.O == p.O
+}
+
+// Hashes consistently with Equals.
+func (o *GoObject) Hash() uint32 {
+	if reflect.TypeOf(o.O).Comparable() {
+		return hashValue(reflect.ValueOf(o.O))
+	}
+	return hashString(fmt.Sprintf("%p", o))
+}
+
+// Hashes v such that values that are == (as Go compares them, e.g.
+// 0.0 and -0.0, or pointers to the same variable) hash the same.
+func hashValue(v reflect.Value) uint32 {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return hashBits(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return hashBits(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return hashFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return hashFloat(real(v.Complex()))*31 + hashFloat(imag(v.Complex()))
+	case reflect.String:
+		return hashString(v.String())
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return hashBits(uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return hashValue(v.Elem())
+	case reflect.Array:
+		h := uint32(0)
+		for i := 0; i < v.Len(); i++ {
+			h = h*31 + hashValue(v.Index(i))
+		}
+		return h
+	case reflect.Struct:
+		h := uint32(0)
+		for i := 0; i < v.NumField(); i++ {
+			h = h*31 + hashValue(v.Field(i))
+		}
+		return h
+	}
+	return 0
+}
+
+func hashBits(b uint64) uint32 {
+	return uint32(b ^ b>>32)
+}
+
+func hashFloat(f float64) uint32 {
+	if f == 0 {
+		f = 0 // -0.0 == 0.0
+	}
+	return hashBits(math.Float64bits(f))
+}
+
+func (o *GoObject) GetInfo() *ObjectInfo {
+	return o.info
+}
+
+func (o *GoObject) WithInfo(info *ObjectInfo) Object {
+	res := *o
+	res.info = info
+	return &res
+}
+
+func (o *GoObject) GetType() *Type {
+	return goObjectType
+}
+`
+
+var runtimeRoundTrip = `package gostd
+
+import (
+	"reflect"
+
+	. "github.com/candid82/joker/core"
+)
+
+var goValueKeyword = MakeKeyword("go-value")
+
+// TagGoValue is like TagGoType, but also records p, a pointer to the
+// Go value from which the map o was converted, as :go-value in o's
+// metadata, so that ToGoValue can start with it.
+func TagGoValue(o Object, t string, p interface{}) Object {
+	if m, ok := o.(*ArrayMap); ok {
+		meta := EmptyArrayMap()
+		meta.Add(goTypeKeyword, MakeString(t))
+		meta.Add(goValueKeyword, MakeGoObject(p))
+		return m.WithMeta(meta)
+	}
+	return o
+}
+
+// ToGoValue converts o to a Go value, stored in *p. A map converted
+// from a Go struct yields a copy of that struct, including its
+// unexported fields, with the exported fields that were changed (in
+// Joker) set accordingly.
+func ToGoValue(o Object, p interface{}) {
+	dst := reflect.ValueOf(p).Elem()
+	if msg := toGoValue(o, dst); msg != "" {
+		panic(RT.NewError("Cannot convert " + o.ToString(true) + " to Go " + typeName(dst.Type()) + ": " + msg))
+	}
+}
+
+// Returns the Go value (a pointer) recorded in o's metadata, if any.
+func goValueOf(o Object) reflect.Value {
+	if m, ok := o.(Meta); ok {
+		if meta := m.GetMeta(); meta != nil {
+			if ok, v := meta.Get(goValueKeyword); ok {
+				if g, ok := v.(*GoObject); ok {
+					return reflect.ValueOf(g.O)
+				}
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// Stores o, converted, in dst, returning "" or why it can't.
+func toGoValue(o Object, dst reflect.Value) string {
+	t := dst.Type()
+	switch v := o.(type) {
+	case Nil:
+		dst.Set(reflect.Zero(t))
+		return ""
+	case *GoObject:
+		src := reflect.ValueOf(v.O)
+		switch {
+		case src.Type().AssignableTo(t):
+			dst.Set(src)
+		case src.Kind() == reflect.Ptr && src.Type().Elem().AssignableTo(t):
+			dst.Set(src.Elem())
+		case t.Kind() == reflect.Ptr && src.Type().AssignableTo(t.Elem()):
+			n := reflect.New(t.Elem())
+			n.Elem().Set(src)
+			dst.Set(n)
+		default:
+			return "wrong type of Go value"
+		}
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		n := reflect.New(t.Elem())
+		if msg := toGoValue(o, n.Elem()); msg != "" {
+			return msg
+		}
+		dst.Set(n)
+		return ""
+	case reflect.Struct:
+		m, ok := o.(Map)
+		if !ok {
+			return "expected a map"
+		}
+		orig := goValueOf(o)
+		fromOrig := orig.IsValid() && orig.Type() == reflect.PtrTo(t) && !orig.IsNil()
+		if fromOrig {
+			dst.Set(orig.Elem())
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" { // Unexported
+				continue
+			}
+			ok, val := m.Get(MakeKeyword(f.Name))
+			if !ok || (fromOrig && unchanged(dst.Field(i), val)) {
+				continue
+			}
+			if msg := toGoValue(val, dst.Field(i)); msg != "" {
+				return f.Name + ": " + msg
+			}
+		}
+		return ""
+	case reflect.Interface:
+		x := ToGo(o)
+		if x == nil {
+			dst.Set(reflect.Zero(t))
+			return ""
+		}
+		if xv := reflect.ValueOf(x); xv.Type().AssignableTo(t) {
+			dst.Set(xv)
+			return ""
+		}
+	case reflect.String:
+		if s, ok := o.(String); ok {
+			dst.SetString(s.S)
+			return ""
+		}
+	case reflect.Bool:
+		if b, ok := o.(Bool); ok {
+			dst.SetBool(b.B)
+			return ""
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := o.(Int); ok {
+			dst.SetInt(int64(n.I))
+			return ""
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := o.(Int); ok && n.I >= 0 {
+			dst.SetUint(uint64(n.I))
+			return ""
+		}
+	case reflect.Float32, reflect.Float64:
+		switch n := o.(type) {
+		case Double:
+			dst.SetFloat(n.D)
+			return ""
+		case Int:
+			dst.SetFloat(float64(n.I))
+			return ""
+		}
+	case reflect.Slice:
+		if s, ok := o.(Seqable); ok {
+			res := reflect.MakeSlice(t, 0, 0)
+			for s := s.Seq(); !s.IsEmpty(); s = s.Rest() {
+				e := reflect.New(t.Elem()).Elem()
+				if msg := toGoValue(s.First(), e); msg != "" {
+					return msg
+				}
+				res = reflect.Append(res, e)
+			}
+			dst.Set(res)
+			return ""
+		}
+	case reflect.Map:
+		if m, ok := o.(Map); ok {
+			res := reflect.MakeMap(t)
+			for iter := m.Iter(); iter.HasNext(); {
+				p := iter.Next()
+				k := reflect.New(t.Key()).Elem()
+				v := reflect.New(t.Elem()).Elem()
+				if msg := toGoValue(p.Key, k); msg != "" {
+					return msg
+				}
+				if msg := toGoValue(p.Value, v); msg != "" {
+					return msg
+				}
+				res.SetMapIndex(k, v)
+			}
+			dst.Set(res)
+			return ""
+		}
+	}
+	return "unsupported conversion"
+}
+
+// Whether val (from a map) is as converted from the Go field f. Fields
+// that can't be represented in Joker (e.g. funcs) count as unchanged.
+func unchanged(f reflect.Value, val Object) bool {
+	switch f.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Interface:
+		return true
+	}
+	return fromGo(f).Equals(val)
+}
+`
+
+var runtimeLazy = `package gostd
+
+import (
+	. "github.com/candid82/joker/core"
+)
+
+// A thunk computes (once realized by a LazySeq) the rest of a sequence.
+type thunk func() Object
+
+func (f thunk) Call(args []Object) Object {
+	return f()
+}
+
+// LazySlice returns a lazy sequence of the n elements of a Go slice,
+// each converted (by elem, given its index) only when first needed.
+func LazySlice(n int, elem func(i int) Object) Object {
+	return lazySlice(0, n, elem)
+}
+
+func lazySlice(i, n int, elem func(int) Object) Seq {
+	return NewLazySeq(thunk(func() Object {
+		if i >= n {
+			return NIL
+		}
+		return NewConsSeq(elem(i), lazySlice(i+1, n, elem))
+	}))
+}
+`
+
+var runtimeRaw = `package gostd
+
+import (
+	"sync"
+
+	. "github.com/candid82/joker/core"
+)
+
+// Looked up (under the lock, as futures' goroutines may get here
+// too) once go.gostd has been loaded, which may follow earlier calls.
+var (
+	rawResultsLock sync.Mutex
+	rawResultsVar  *Var
+)
+
+// RawResults returns whether go.gostd/*raw-results* is (currently)
+// true, in which case generated functions return their results as
+// handles instead of converting them to Joker data.
+func RawResults() bool {
+	rawResultsLock.Lock()
+	if rawResultsVar == nil {
+		if ns := GLOBAL_ENV.FindNamespace(MakeSymbol("go.gostd")); ns != nil {
+			rawResultsVar = ns.Resolve("*raw-results*")
+		}
+	}
+	v := rawResultsVar
+	rawResultsLock.Unlock()
+	if v == nil {
+		return false
+	}
+	b, ok := v.Resolve().(Bool)
+	return ok && b.B
+}
+
+// RawResult returns a handle for the Go value of a single result, or
+// a vector of handles for multiple results.
+func RawResult(results ...interface{}) Object {
+	if len(results) == 1 {
+		return MakeGoObject(results[0])
+	}
+	res := EmptyVector
+	for _, r := range results {
+		res = res.Conjoin(MakeGoObject(r))
+	}
+	return res
+}
+`
+
+var runtimeStreams = `package gostd
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	. "github.com/candid82/joker/core"
+)
+
+// ToReader converts a Joker object to an io.Reader: a String is read
+// from directly, while a File, *in*, or Go reader is read as is.
+func ToReader(o Object) io.Reader {
+	switch v := o.(type) {
+	case String:
+		return strings.NewReader(v.S)
+	case *File:
+		return v.File
+	case *BufferedReader:
+		return v.Reader
+	case *IOReader:
+		return v.Reader
+	case *GoObject:
+		if r, ok := v.O.(io.Reader); ok {
+			return r
+		}
+	}
+	panic(RT.NewError("Expected String, File, or reader, got " + o.ToString(true)))
+}
+
+// ToWriter converts a Joker object (a File, *out*, *err*, or Go
+// writer) to an io.Writer.
+func ToWriter(o Object) io.Writer {
+	switch v := o.(type) {
+	case *File:
+		return v.File
+	case *IOWriter:
+		return v.Writer
+	case *GoObject:
+		if w, ok := v.O.(io.Writer); ok {
+			return w
+		}
+	}
+	panic(RT.NewError("Expected File or writer, got " + o.ToString(true)))
+}
+
+// ToBufioReader is like ToReader, but reuses an existing
+// *bufio.Reader (such as that underlying *in*) when there is one.
+func ToBufioReader(o Object) *bufio.Reader {
+	switch v := o.(type) {
+	case *BufferedReader:
+		return v.Reader
+	case *GoObject:
+		if r, ok := v.O.(*bufio.Reader); ok {
+			return r
+		}
+	}
+	return bufio.NewReader(ToReader(o))
+}
+
+// ToBufioWriter is like ToWriter, but reuses an existing
+// *bufio.Writer when there is one. A new one is flushed only by the
+// Go code to which it is passed.
+func ToBufioWriter(o Object) *bufio.Writer {
+	if v, ok := o.(*GoObject); ok {
+		if w, ok := v.O.(*bufio.Writer); ok {
+			return w
+		}
+	}
+	return bufio.NewWriter(ToWriter(o))
+}
+
+func readAll(rdr Object) Object {
+	b, err := ioutil.ReadAll(ToReader(rdr))
+	if err != nil {
+		ThrowError("go.gostd/read-all", err)
+	}
+	return MakeString(string(b))
+}
+
+func readLines(rdr Object) Object {
+	res := EmptyVector
+	s := bufio.NewScanner(ToReader(rdr))
+	for s.Scan() {
+		res = res.Conjoin(MakeString(s.Text()))
+	}
+	if err := s.Err(); err != nil {
+		ThrowError("go.gostd/read-lines", err)
+	}
+	return res
+}
+
+func write(w Object, s Object) Object {
+	wr := ToWriter(w)
+	if bw, ok := wr.(*bufio.Writer); ok {
+		defer bw.Flush()
+	}
+	n, err := io.WriteString(wr, StringOf(s))
+	if err != nil {
+		ThrowError("go.gostd/write", err)
+	}
+	return MakeInt(n)
+}
+`
+
+var runtimeContext = `package gostd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	. "github.com/candid82/joker/core"
+)
+
+// A cancellable context, as returned by go.gostd/context.
+type cancellable struct {
+	context.Context
+	cancel context.CancelFunc
+}
+
+// Contexts of calls in flight, cancelled upon SIGINT. SIGINT is
+// caught only while there are any.
+var inFlight = struct {
+	sync.Mutex
+	cancels map[int]context.CancelFunc
+	next    int
+	sigs    chan os.Signal
+	once    sync.Once
+}{
+	cancels: map[int]context.CancelFunc{},
+	sigs:    make(chan os.Signal, 1),
+}
+
+// ToContext converts a Joker object to the context.Context passed to
+// a Go function: nil yields a new context, an Int one that times out
+// after that many milliseconds, and a context handle itself. The
+// caller must defer the returned function, until which time SIGINT
+// cancels the context (unless it's a handle to one not made by
+// go.gostd/context, so not cancellable here).
+//
+// A new context isn't cancelled when the call returns, as it may
+// outlive the call (e.g. in a returned request); being derived from
+// no other, it is then simply garbage collected.
+func ToContext(o Object) (context.Context, func()) {
+	switch v := o.(type) {
+	case Nil:
+		ctx, cancel := context.WithCancel(context.Background())
+		return ctx, interruptible(cancel)
+	case Int:
+		ctx, cancel := withTimeout(context.Background(), v.I)
+		return ctx, interruptible(cancel)
+	case *GoObject:
+		switch c := v.O.(type) {
+		case *cancellable:
+			return c.Context, interruptible(c.cancel)
+		case context.Context:
+			return c, func() {}
+		}
+	}
+	panic(RT.NewError("Expected nil, Int (milliseconds), or Go context, got " + o.ToString(true)))
+}
+
+func withTimeout(parent context.Context, ms int) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, time.Duration(ms)*time.Millisecond)
+}
+
+func interruptible(cancel context.CancelFunc) func() {
+	inFlight.once.Do(func() {
+		go func() {
+			for range inFlight.sigs {
+				inFlight.Lock()
+				for _, c := range inFlight.cancels {
+					c()
+				}
+				inFlight.Unlock()
+			}
+		}()
+	})
+	inFlight.Lock()
+	defer inFlight.Unlock()
+	if len(inFlight.cancels) == 0 {
+		signal.Notify(inFlight.sigs, os.Interrupt)
+	}
+	id := inFlight.next
+	inFlight.next++
+	inFlight.cancels[id] = cancel
+	return func() {
+		inFlight.Lock()
+		defer inFlight.Unlock()
+		delete(inFlight.cancels, id)
+		if len(inFlight.cancels) == 0 {
+			signal.Stop(inFlight.sigs)
+		}
+	}
+}
+
+func newContext(timeout Object) Object {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	switch v := timeout.(type) {
+	case Nil:
+		ctx, cancel = context.WithCancel(context.Background())
+	case Int:
+		ctx, cancel = withTimeout(context.Background(), v.I)
+	default:
+		panic(RT.NewArgTypeError(0, timeout, "Int"))
+	}
+	return MakeGoObject(&cancellable{ctx, cancel})
+}
+
+func cancelContext(ctx Object) Object {
+	if v, ok := ctx.(*GoObject); ok {
+		if c, ok := v.O.(*cancellable); ok {
+			c.cancel()
+			return NIL
+		}
+	}
+	panic(RT.NewArgTypeError(0, ctx, "GoObject (context)"))
+}
+`
+
+var runtimeCallbacks = `package gostd
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/candid82/joker/core"
+)
+
+// The Joker evaluator isn't safe for concurrent use, so only the
+// goroutine holding the evaluator token may run Joker code. The Joker
+// thread holds it except while waiting on Go code (see ReleaseEval),
+// when Joker fns called back by Go (on any goroutine) take turns
+// holding it. The token is available when it is in the channel.
+var evalToken = make(chan struct{}, 1)
+
+// Limits on callbacks waiting for the evaluator token, as set by
+// go.gostd/set-callback-queue!; zero means no limit.
+var callbackQueue struct {
+	sync.Mutex
+	waiting int
+	max     int
+	timeout time.Duration
+}
+
+var (
+	ErrCallbackQueueFull = errors.New("too many Joker callbacks waiting to run")
+	ErrCallbackTimeout   = errors.New("timed out waiting to run Joker callback")
+)
+
+// ReleaseEval gives up the evaluator token, returning a function,
+// which the caller defers, that takes it back.
+func ReleaseEval() func() {
+	evalToken <- struct{}{}
+	return func() {
+		<-evalToken
+	}
+}
+
+// Runs f, which runs Joker code, on a goroutine other than the Joker
+// thread once it's that goroutine's turn (as for Call, but without
+// the callback queue's limits, as f is making an exception to report
+// a failure).
+func withEval(f func() Object) Object {
+	<-evalToken
+	defer func() {
+		evalToken <- struct{}{}
+	}()
+	return f()
+}
+
+func acquireEval() {
+	callbackQueue.Lock()
+	if callbackQueue.max > 0 && callbackQueue.waiting >= callbackQueue.max {
+		callbackQueue.Unlock()
+		panic(ErrCallbackQueueFull)
+	}
+	callbackQueue.waiting++
+	timeout := callbackQueue.timeout
+	callbackQueue.Unlock()
+	defer func() {
+		callbackQueue.Lock()
+		callbackQueue.waiting--
+		callbackQueue.Unlock()
+	}()
+	if timeout <= 0 {
+		<-evalToken
+		return
+	}
+	select {
+	case <-evalToken:
+	case <-time.After(timeout):
+		panic(ErrCallbackTimeout)
+	}
+}
+
+// CallbackOf checks that o, passed for a Go callback, can be called.
+func CallbackOf(o Object) Callable {
+	if fn, ok := o.(Callable); ok {
+		return fn
+	}
+	panic(RT.NewArgTypeError(0, o, "Callable"))
+}
+
+// Call calls the Joker fn, on behalf of Go code, once it holds the
+// evaluator token. It panics with ErrCallbackQueueFull or
+// ErrCallbackTimeout if that can't happen soon enough.
+func Call(fn Callable, args ...Object) Object {
+	acquireEval()
+	defer func() {
+		evalToken <- struct{}{}
+	}()
+	return fn.Call(args)
+}
+
+// ErrorOf converts the result of a Joker callback to a Go error.
+func ErrorOf(o Object) error {
+	switch v := o.(type) {
+	case Nil:
+		return nil
+	case error:
+		return v
+	}
+	return errors.New(o.ToString(false))
+}
+
+func setCallbackQueue(max Object, timeout Object) Object {
+	callbackQueue.Lock()
+	defer callbackQueue.Unlock()
+	callbackQueue.max = IntOf(max)
+	callbackQueue.timeout = time.Duration(IntOf(timeout)) * time.Millisecond
+	return NIL
+}
+`
+
+var runtimeTypes = `package gostd
+
+import (
+	"reflect"
+
+	. "github.com/candid82/joker/core"
+)
+
+var goTypeKeyword = MakeKeyword("go-type")
+
+// TagGoType records t, the name of the Go type from which the map o
+// was converted, as :go-type in o's metadata.
+func TagGoType(o Object, t string) Object {
+	if m, ok := o.(*ArrayMap); ok {
+		meta := EmptyArrayMap()
+		meta.Add(goTypeKeyword, MakeString(t))
+		return m.WithMeta(meta)
+	}
+	return o
+}
+
+// GoTypeOf returns the package-qualified name (e.g. "net/url.URL" or
+// "*net/url.URL") of the Go type of o: that of the value wrapped by a
+// GoObject, or the type a map was converted from. Returns "" if o is
+// neither.
+func GoTypeOf(o Object) string {
+	switch v := o.(type) {
+	case *GoObject:
+		return typeName(reflect.TypeOf(v.O))
+	case Meta:
+		if meta := v.GetMeta(); meta != nil {
+			if ok, t := meta.Get(goTypeKeyword); ok {
+				if s, ok := t.(String); ok {
+					return s.S
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// Named Go types (by their package-qualified names), as registered
+// by generated code for the types with predicates.
+var goTypes = map[string]reflect.Type{}
+
+// RegisterGoType is called (by generated code) for each type with a
+// predicate.
+func RegisterGoType(t reflect.Type) {
+	goTypes[typeName(t)] = t
+}
+
+// IsGoType returns whether o is (or points to, or was converted
+// from) a value of the Go type t, or, if t is a (registered)
+// interface type, of a type implementing it.
+func IsGoType(o Object, t string) bool {
+	ot := GoTypeOf(o)
+	if ot == t || ot == "*"+t {
+		return true
+	}
+	it, ok := goTypes[t]
+	if !ok || it.Kind() != reflect.Interface {
+		return false
+	}
+	var vt reflect.Type
+	if g, ok := o.(*GoObject); ok {
+		vt = reflect.TypeOf(g.O)
+	} else if vt, ok = goTypes[ot]; !ok {
+		return false // Not a map converted from a registered type
+	}
+	return vt.Implements(it) || (vt.Kind() != reflect.Ptr && reflect.PtrTo(vt).Implements(it))
+}
+
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + typeName(t.Elem())
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+func goType(o Object) Object {
+	if t := GoTypeOf(o); t != "" {
+		return MakeString(t)
+	}
+	return NIL
+}
+`
+
+var runtimeFutures = `package gostd
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/candid82/joker/core"
+)
+
+// A Future is the result of a Go function called asynchronously (see
+// --async). Deref'ing it waits for, then returns, the result, or
+// throws the exception thrown by the call.
+type Future struct {
+	done     chan struct{}
+	result   Object
+	err      Error
+	errorKey Object
+	info     *ObjectInfo
+}
+
+var futureType = RegRefType("GoFuture", (*Future)(nil), "The eventual result of an asynchronous Go call.")
+
+// Async calls f, on behalf of the Go function fn, in a new goroutine,
+// returning a Future for its result. errorKey is the index (or key)
+// of the error among fn's results, or NIL if its result is just that.
+// As f runs off the Joker thread, it makes exceptions via
+// ThrowErrorAsync and MakeGoErrorAsync, which (like Async, given a
+// panic) wait until the Joker thread waits, e.g. on the Future.
+func Async(fn string, errorKey Object, f func() Object) *Future {
+	fut := &Future{done: make(chan struct{}), errorKey: errorKey}
+	go func() {
+		defer close(fut.done)
+		defer func() {
+			if r := recover(); r != nil {
+				if err, ok := r.(Error); ok {
+					fut.err = err
+				} else {
+					fut.err = withEval(func() Object { return panicException(fn, r) }).(Error)
+				}
+			}
+		}()
+		fut.result = f()
+	}()
+	return fut
+}
+
+// Waits, letting the call run Joker code meanwhile, until the call is
+// done (returning true) or the timeout (if not nil) expires.
+func (fut *Future) wait(timeout <-chan time.Time) bool {
+	select {
+	case <-fut.done:
+		return true
+	default:
+	}
+	defer ReleaseEval()()
+	select {
+	case <-fut.done:
+		return true
+	case <-timeout:
+		return false
+	}
+}
+
+func (fut *Future) Deref() Object {
+	fut.wait(nil)
+	if fut.err != nil {
+		panic(fut.err)
+	}
+	return fut.result
+}
+
+func (fut *Future) IsDone() bool {
+	select {
+	case <-fut.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (fut *Future) ToString(escape bool) string {
+	status := "pending"
+	if fut.IsDone() {
+		status = "done"
+	}
+	return "#object[GoFuture " + status + "]"
+}
+
+func (fut *Future) Equals(other interface{}) bool {
+	return fut == other
+}
+
+func (fut *Future) GetInfo() *ObjectInfo {
+	return fut.info
+}
+
+func (fut *Future) WithInfo(info *ObjectInfo) Object {
+	res := *fut
+	res.info = info
+	return &res
+}
+
+func (fut *Future) GetType() *Type {
+	return futureType
+}
+
+func (fut *Future) Hash() uint32 {
+	return hashString(fmt.Sprintf("%p", fut.done))
+}
+
+func futureOf(o Object) *Future {
+	if fut, ok := o.(*Future); ok {
+		return fut
+	}
+	panic(RT.NewArgTypeError(0, o, "GoFuture"))
+}
+
+func await(fut Object, timeout Object, timeoutVal Object) Object {
+	f := futureOf(fut)
+	if timeout != NIL && !f.wait(time.After(time.Duration(IntOf(timeout))*time.Millisecond)) {
+		return timeoutVal
+	}
+	return f.Deref()
+}
+
+func futureDone(fut Object) Object {
+	return MakeBool(futureOf(fut).IsDone())
+}
+
+func futureError(fut Object) Object {
+	f := futureOf(fut)
+	f.wait(nil)
+	if f.err != nil {
+		return f.err
+	}
+	res := f.result
+	if f.errorKey != NIL {
+		if g, ok := res.(Gettable); ok { // A vector (or map) of results
+			if ok, v := g.Get(f.errorKey); ok {
+				res = v
+			}
+		}
+	}
+	if err, ok := res.(Error); ok {
+		return err
+	}
+	return NIL
+}
+`
+
+// Any package whose generated Go code refers to the support package
+// must import it.
+func usesRuntime(goFn string) bool {
+	return strings.Contains(goFn, runtimePrefix)
+}
+
+func (g *Generator) genRuntime(jokerLibDir string) (files []*OutputFile) {
+	dir := filepath.Join(jokerLibDir, runtimePkg)
+	jf := filepath.Join(jokerLibDir, runtimePkg+".joke")
+	files = append(files, &OutputFile{Path: jf, Kind: RuntimeFile, Perm: 0777,
+		Contents: ";;;; Auto-generated by gostd2joker at " + g.curTimeAndVersion() + ", do not edit!!\n" + runtimeJoke})
+	sortedCodeMap(runtimeFiles,
+		func(f string, w string) {
+			files = append(files, &OutputFile{Path: filepath.Join(dir, f), Kind: RuntimeFile, Perm: 0666,
+				Contents: fmt.Sprintf("// Auto-generated by gostd2joker at %s, do not edit!!\n\n%s", g.curTimeAndVersion(), w)})
+		})
+	return
+}
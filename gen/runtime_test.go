@@ -0,0 +1,592 @@
+package gen
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+/* The support package's code can run only in a Joker tree, so these
+/* tests build it against a minimal stand-in for Joker's core package
+/* (implementing just what the tests exercise), in a temporary
+/* GOPATH. */
+
+const stubCore = `package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+type ObjectInfo struct{}
+
+type Type struct{ name string }
+
+type Equality interface {
+	Equals(interface{}) bool
+}
+
+type Object interface {
+	Equality
+	ToString(escape bool) string
+	GetInfo() *ObjectInfo
+	WithInfo(*ObjectInfo) Object
+	GetType() *Type
+	Hash() uint32
+}
+
+type Error interface {
+	error
+	Object
+	Message() Object
+}
+
+type Meta interface {
+	GetMeta() Map
+	WithMeta(Map) Object
+}
+
+type Seq interface {
+	Object
+	First() Object
+	Rest() Seq
+	IsEmpty() bool
+	Cons(obj Object) Seq
+}
+
+type Seqable interface {
+	Seq() Seq
+}
+
+type Counted interface {
+	Count() int
+}
+
+type Callable interface {
+	Call(args []Object) Object
+}
+
+type Deref interface {
+	Deref() Object
+}
+
+type Gettable interface {
+	Get(key Object) (bool, Object)
+}
+
+type Map interface {
+	Object
+	Get(key Object) (bool, Object)
+	Iter() MapIterator
+	Count() int
+}
+
+type MapIterator interface {
+	HasNext() bool
+	Next() *Pair
+}
+
+type Pair struct{ Key, Value Object }
+
+type base struct{}
+
+func (b base) Equals(interface{}) bool     { return false }
+func (b base) ToString(escape bool) string { return "" }
+func (b base) GetInfo() *ObjectInfo        { return nil }
+func (b base) GetType() *Type              { return nil }
+func (b base) Hash() uint32                { return 0 }
+
+type InfoHolder struct{ info *ObjectInfo }
+
+func (h InfoHolder) GetInfo() *ObjectInfo { return h.info }
+
+type MetaHolder struct{ meta Map }
+
+func (m MetaHolder) GetMeta() Map { return m.meta }
+
+type Nil struct{ base }
+
+func (n Nil) WithInfo(*ObjectInfo) Object  { return n }
+func (n Nil) ToString(escape bool) string { return "nil" }
+
+var NIL = Nil{}
+
+type String struct {
+	base
+	S string
+}
+
+func (x String) WithInfo(*ObjectInfo) Object  { return x }
+func (x String) ToString(escape bool) string { return x.S }
+
+type Int struct {
+	base
+	I int
+}
+
+func (x Int) WithInfo(*ObjectInfo) Object  { return x }
+func (x Int) ToString(escape bool) string { return fmt.Sprint(x.I) }
+
+type Double struct {
+	base
+	D float64
+}
+
+func (x Double) WithInfo(*ObjectInfo) Object { return x }
+
+type Bool struct {
+	base
+	B bool
+}
+
+func (x Bool) WithInfo(*ObjectInfo) Object { return x }
+
+type Char struct {
+	base
+	Ch rune
+}
+
+func (x Char) WithInfo(*ObjectInfo) Object { return x }
+
+type Keyword struct {
+	base
+	name string
+}
+
+func (x Keyword) WithInfo(*ObjectInfo) Object  { return x }
+func (x Keyword) ToString(escape bool) string { return ":" + x.name }
+func (x Keyword) Name() string                { return x.name }
+
+type Symbol struct {
+	base
+	name string
+}
+
+func (x Symbol) WithInfo(*ObjectInfo) Object { return x }
+func (x Symbol) Name() string                { return x.name }
+
+type GoError struct {
+	base
+	Err error
+}
+
+func (x GoError) WithInfo(*ObjectInfo) Object { return x }
+
+func MakeString(s string) String   { return String{S: s} }
+func MakeInt(i int) Int            { return Int{I: i} }
+func MakeDouble(d float64) Double  { return Double{D: d} }
+func MakeBool(b bool) Bool         { return Bool{B: b} }
+func MakeKeyword(s string) Keyword { return Keyword{name: s} }
+func MakeSymbol(s string) Symbol   { return Symbol{name: s} }
+func MakeError(err error) GoError  { return GoError{Err: err} }
+func MakeChar(r rune) Char         { return Char{Ch: r} }
+
+type ArrayMap struct {
+	base
+	MetaHolder
+	arr []Object
+}
+
+func (m *ArrayMap) WithInfo(*ObjectInfo) Object { return m }
+func (m *ArrayMap) WithMeta(Map) Object         { return m }
+func (m *ArrayMap) Add(k, v Object) bool        { m.arr = append(m.arr, k, v); return true }
+func (m *ArrayMap) Count() int                  { return len(m.arr) / 2 }
+func (m *ArrayMap) Assoc(k, v Object) Map       { m.Add(k, v); return m }
+
+func (m *ArrayMap) Get(key Object) (bool, Object) {
+	for i := 0; i < len(m.arr); i += 2 {
+		if m.arr[i] == key {
+			return true, m.arr[i+1]
+		}
+	}
+	return false, nil
+}
+
+type mapIterator struct {
+	m *ArrayMap
+	i int
+}
+
+func (it *mapIterator) HasNext() bool { return it.i < len(it.m.arr) }
+
+func (it *mapIterator) Next() *Pair {
+	it.i += 2
+	return &Pair{it.m.arr[it.i-2], it.m.arr[it.i-1]}
+}
+
+func (m *ArrayMap) Iter() MapIterator { return &mapIterator{m: m} }
+
+func EmptyArrayMap() *ArrayMap { return &ArrayMap{} }
+
+type Vector struct {
+	base
+	MetaHolder
+	arr []Object
+}
+
+func (v *Vector) WithInfo(*ObjectInfo) Object   { return v }
+func (v *Vector) WithMeta(Map) Object           { return v }
+func (v *Vector) Get(key Object) (bool, Object) { return false, nil }
+func (v *Vector) Conjoin(o Object) *Vector      { return &Vector{arr: append(append([]Object{}, v.arr...), o)} }
+func (v *Vector) Count() int                    { return len(v.arr) }
+func (v *Vector) At(i int) Object               { return v.arr[i] }
+
+func (v *Vector) Seq() Seq {
+	var s Seq = emptySeq{}
+	for i := len(v.arr) - 1; i >= 0; i-- {
+		s = s.Cons(v.arr[i])
+	}
+	return s
+}
+
+var EmptyVector = &Vector{}
+
+type emptySeq struct{ base }
+
+func (s emptySeq) WithInfo(*ObjectInfo) Object { return s }
+func (s emptySeq) First() Object               { return NIL }
+func (s emptySeq) Rest() Seq                   { return s }
+func (s emptySeq) IsEmpty() bool               { return true }
+func (s emptySeq) Cons(obj Object) Seq         { return &ConsSeq{first: obj, rest: s} }
+
+type ExInfo struct {
+	ArrayMap
+}
+
+func (e *ExInfo) Error() string   { return "" }
+func (e *ExInfo) Message() Object { return NIL }
+
+type EvalError struct {
+	base
+	msg string
+}
+
+func (e *EvalError) WithInfo(*ObjectInfo) Object { return e }
+func (e *EvalError) Error() string               { return e.msg }
+func (e *EvalError) Message() Object             { return MakeString(e.msg) }
+
+type Runtime struct{}
+
+func (rt *Runtime) NewError(msg string) *EvalError { return &EvalError{msg: msg} }
+
+func (rt *Runtime) NewArgTypeError(index int, o Object, expected string) *EvalError {
+	return &EvalError{msg: fmt.Sprintf("arg %d: %s is not a %s", index, o.ToString(false), expected)}
+}
+
+var RT = &Runtime{}
+
+type Var struct {
+	base
+	Value Object
+}
+
+func (v *Var) WithInfo(*ObjectInfo) Object { return v }
+func (v *Var) Resolve() Object             { return v.Value }
+
+type Namespace struct {
+	base
+	Vars map[string]*Var
+}
+
+func (ns *Namespace) WithInfo(*ObjectInfo) Object { return ns }
+func (ns *Namespace) Resolve(name string) *Var    { return ns.Vars[name] }
+
+type Env struct {
+	Namespaces map[string]*Namespace
+}
+
+func (env *Env) FindNamespace(s Symbol) *Namespace { return env.Namespaces[s.name] }
+
+var GLOBAL_ENV = &Env{Namespaces: map[string]*Namespace{}}
+
+type File struct {
+	base
+	*os.File
+}
+
+func RegRefType(name string, inst interface{}, doc string) *Type { return &Type{name: name} }
+
+type BufferedReader struct {
+	base
+	*bufio.Reader
+}
+
+type IOReader struct {
+	base
+	io.Reader
+}
+
+type IOWriter struct {
+	base
+	io.Writer
+}
+
+func (x *File) WithInfo(*ObjectInfo) Object           { return x }
+func (x *BufferedReader) WithInfo(*ObjectInfo) Object { return x }
+func (x *IOReader) WithInfo(*ObjectInfo) Object       { return x }
+func (x *IOWriter) WithInfo(*ObjectInfo) Object       { return x }
+
+type LazySeq struct {
+	base
+	fn Callable
+}
+
+func (s *LazySeq) WithInfo(*ObjectInfo) Object { return s }
+func (s *LazySeq) First() Object               { return NIL }
+func (s *LazySeq) Rest() Seq                   { return s }
+func (s *LazySeq) IsEmpty() bool               { return true }
+func (s *LazySeq) Cons(obj Object) Seq         { return &ConsSeq{first: obj, rest: s} }
+
+func NewLazySeq(c Callable) *LazySeq { return &LazySeq{fn: c} }
+
+type ConsSeq struct {
+	base
+	first Object
+	rest  Seq
+}
+
+func (s *ConsSeq) WithInfo(*ObjectInfo) Object { return s }
+func (s *ConsSeq) First() Object               { return s.first }
+func (s *ConsSeq) Rest() Seq                   { return s.rest }
+func (s *ConsSeq) IsEmpty() bool               { return false }
+func (s *ConsSeq) Cons(obj Object) Seq         { return &ConsSeq{first: obj, rest: s} }
+
+func NewConsSeq(first Object, rest Seq) *ConsSeq { return &ConsSeq{first: first, rest: rest} }
+`
+
+const callbacksTest = `package gostd
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/candid82/joker/core"
+)
+
+type fn func(args []Object) Object
+
+func (f fn) Call(args []Object) Object {
+	return f(args)
+}
+
+func callFromGoroutine(f fn) chan interface{} {
+	res := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				res <- r
+			}
+		}()
+		res <- Call(f, MakeInt(42))
+	}()
+	return res
+}
+
+func TestCallbackRunsWhileJokerThreadWaits(t *testing.T) {
+	res := callFromGoroutine(func(args []Object) Object { return args[0] })
+	defer ReleaseEval()() // As a generated wrapper does while Go code runs
+	select {
+	case r := <-res:
+		if r != MakeInt(42) {
+			t.Errorf("callback returned %v, not 42", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback didn't run while the Joker thread waited")
+	}
+}
+
+func TestCallbackWaitsForJokerThread(t *testing.T) {
+	setCallbackQueue(MakeInt(0), MakeInt(100))
+	defer setCallbackQueue(MakeInt(0), MakeInt(0))
+	ran := false
+	res := callFromGoroutine(func(args []Object) Object { ran = true; return NIL })
+	if r := <-res; r != ErrCallbackTimeout || ran {
+		t.Errorf("callback, while the Joker thread was busy, got %v (ran: %v), not %v", r, ran, ErrCallbackTimeout)
+	}
+}
+`
+
+const convertTest = `package gostd
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/candid82/joker/core"
+)
+
+type point struct{ x, y int }
+
+func (p *point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.x, p.y)
+}
+
+// As a Go function taking an interface{} would.
+func received(x interface{}) interface{} {
+	return x
+}
+
+func TestHandleThroughInterfaceParam(t *testing.T) {
+	p := &point{1, 2}
+	h := MakeGoObject(p)
+	if x := received(ToGo(h)); x != p {
+		t.Errorf("handle to %v passed a %T", p, x)
+	}
+	if x := received(NewOut(h)); *(x.(*point)) != *p {
+		t.Errorf("handle to %v passed a %T out-param", p, x)
+	}
+	if s := fmt.Sprint(ToGoArgs(EmptyVector.Conjoin(MakeString("at ")).Conjoin(h))...); s != "at (1, 2)" {
+		t.Errorf("handle printed as %q", s)
+	}
+}
+
+func TestNamedNonStructType(t *testing.T) {
+	RegisterGoType(reflect.TypeOf((*time.Duration)(nil)).Elem())
+	if !IsGoType(MakeGoObject(time.Second), "time.Duration") {
+		t.Error("handle to a time.Duration not recognized")
+	}
+	d := time.Second
+	if !IsGoType(MakeGoObject(&d), "time.Duration") {
+		t.Error("handle to a *time.Duration not recognized")
+	}
+	if IsGoType(MakeInt(1), "time.Duration") {
+		t.Error("Int recognized as a time.Duration")
+	}
+}
+`
+
+const contextTest = `package gostd
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/candid82/joker/core"
+)
+
+func TestContextPassedItself(t *testing.T) {
+	h := newContext(NIL).(*GoObject)
+	c := h.O.(*cancellable)
+	for i := 0; i < 3; i++ {
+		ctx, done := ToContext(h)
+		if ctx != c.Context {
+			t.Errorf("passed %v, not %v", ctx, c.Context)
+		}
+		done()
+	}
+	if n := len(inFlight.cancels); n != 0 {
+		t.Errorf("%d contexts still in flight", n)
+	}
+	plain := context.WithValue(context.Background(), "k", "v")
+	if ctx, done := ToContext(MakeGoObject(plain)); ctx != plain {
+		t.Errorf("passed %v, not %v", ctx, plain)
+	} else {
+		done()
+	}
+	cancelContext(h)
+	if c.Err() != context.Canceled {
+		t.Errorf("context not cancelled: %v", c.Err())
+	}
+}
+
+func TestContextTimeout(t *testing.T) {
+	ctx, done := ToContext(MakeInt(1))
+	defer done()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("context has no deadline")
+	}
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("context ended with %v", ctx.Err())
+	}
+}
+`
+
+const futuresTest = `package gostd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/candid82/joker/core"
+)
+
+// Stands in for joker.core/ex-info.
+type exInfo struct{ Nil }
+
+func (exInfo) Call(args []Object) Object {
+	return &ExInfo{}
+}
+
+func init() {
+	GLOBAL_ENV.Namespaces["joker.core"] = &Namespace{Vars: map[string]*Var{"ex-info": &Var{Value: exInfo{}}}}
+}
+
+func TestAsyncExceptionsWaitForJokerThread(t *testing.T) {
+	for name, f := range map[string]func() Object{
+		"error": func() Object { ThrowErrorAsync("f", errors.New("failed")); return NIL },
+		"panic": func() Object { panic("failed") },
+	} {
+		fut := Async("f", NIL, f)
+		time.Sleep(50 * time.Millisecond)
+		if fut.IsDone() {
+			t.Errorf("%s: exception made while the Joker thread ran", name)
+		}
+		func() {
+			defer func() {
+				if _, ok := recover().(*ExInfo); !ok {
+					t.Errorf("%s: deref didn't throw the exception", name)
+				}
+			}()
+			fut.Deref()
+		}()
+	}
+}
+`
+
+// Runs the above tests (each a file in the support package) against
+// the support package built on the stand-in core package.
+func TestRuntime(t *testing.T) {
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command")
+	}
+	gopath, err := ioutil.TempDir("", "gostd2joker-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+	joker := filepath.Join(gopath, "src", "github.com", "candid82", "joker")
+	gostd := filepath.Join(joker, "std", "go", "gostd")
+	files := map[string]string{
+		filepath.Join(joker, "core", "core.go"):   stubCore,
+		filepath.Join(gostd, "callbacks_test.go"): callbacksTest,
+		filepath.Join(gostd, "context_test.go"):   contextTest,
+		filepath.Join(gostd, "convert_test.go"):   convertTest,
+		filepath.Join(gostd, "futures_test.go"):   futuresTest,
+	}
+	for f, code := range runtimeFiles {
+		files[filepath.Join(gostd, f)] = code
+	}
+	for f, code := range files {
+		if err := os.MkdirAll(filepath.Dir(f), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(f, []byte(code), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goCmd, "test", "-count=1", ".")
+	cmd.Dir = gostd
+	cmd.Env = append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
@@ -0,0 +1,166 @@
+package gen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+/* The generated files are assembled from text/template templates,
+/* each of which may be replaced by a file of the same name in the
+/* directory given by Options.TemplateDir (--templates), e.g. to add
+/* a license header. Each template is executed with the data shown
+/* next to its name below. */
+
+const (
+	jokeHeaderTemplate      = "joke-header.tmpl"       // JokeHeader: start of each .joke file
+	jokeFuncTemplate        = "joke-func.tmpl"         // JokeFunction: a defn in a .joke file
+	jokeFuncContextTemplate = "joke-func-context.tmpl" // JokeFunction: the same, with an arity that omits a leading context.Context
+	goHeaderTemplate        = "go-header.tmpl"         // GoHeader: start of each *_native.go file
+	goFuncTemplate          = "go-func.tmpl"           // GoFunction: a wrapper function in a *_native.go file
+)
+
+// Data for the start of a package's .joke file.
+type JokeHeader struct {
+	Path      string   // Relative (Unix-style) path of the Go package, e.g. "net/url"
+	Namespace string   // E.g. "go.net.url"
+	Imports   []string // Go packages imported by the generated functions (:go-imports)
+	Empty     bool     // Whether no functions were generated
+	Generated string   // When, and by what version, the file was generated
+}
+
+// Data for the start of a package's *_native.go file.
+type GoHeader struct {
+	Path      string     // Relative (Unix-style) path of the Go package, e.g. "net/url"
+	Name      string     // The package name, e.g. "url"
+	Imports   []GoImport // In order
+	Generated string     // When, and by what version, the file was generated
+}
+
+type GoImport struct {
+	Name string // E.g. "_url", or "." for Joker's core package
+	Path string // E.g. "net/url"
+}
+
+// Data for a Joker function wrapping a Go function.
+type JokeFunction struct {
+	Name       string // Of the Go (and Joker) function, e.g. "Parse"
+	Qualified  string // E.g. "net/url.Parse"
+	ReturnType string // Joker type hint, if the Go function is called directly (e.g. `^"String"`), else ""
+	Doc        string // Docstring, quoted
+	GoCall     string // Go code that makes the call, e.g. "parse(_rawurl)"
+	Params     string // Joker params, e.g. `^String _rawurl`
+
+	// For joke-func-context.tmpl, the arity that omits the leading context.Context:
+	ParamsCount        int    // The number of params it has
+	ParamsWithoutCtx   string // Its params
+	GoCallWithoutCtx   string // The Go code it calls
+	ParamsCountWithCtx int    // The number of params of the full arity
+}
+
+// Data for a Go function (in *_native.go) wrapping a Go function.
+type GoFunction struct {
+	Name       string // E.g. "parse"
+	Qualified  string // Of the wrapped function, e.g. "net/url.Parse"
+	Params     string // E.g. "rawurl string"
+	ReturnType string // E.g. "Object"
+	Body       string // Each line indented by a tab
+}
+
+var defaultTemplates = map[string]string{
+	jokeHeaderTemplate: `;;;; Auto-generated by gostd2joker at {{.Generated}}, do not edit!!
+
+(ns
+  ^{:go-imports [{{range $i, $p := .Imports}}{{if $i}} {{end}}"{{$p}}"{{end}}]
+    :doc "Provides a low-level interface to the {{.Path}} package."
+    :empty {{.Empty}}}
+  {{.Namespace}})
+`,
+	jokeFuncTemplate: `
+(defn {{with .ReturnType}}{{.}} {{end}}{{.Name}}
+  {{.Doc}}
+  {:added "1.0"
+   :go "{{.GoCall}}"}
+  [{{.Params}}])
+`,
+	jokeFuncContextTemplate: `
+(defn {{with .ReturnType}}{{.}} {{end}}{{.Name}}
+  {{.Doc}}
+  {:added "1.0"
+   :go {{"{"}}{{.ParamsCount}} "{{.GoCallWithoutCtx}}"
+        {{.ParamsCountWithCtx}} "{{.GoCall}}"}}
+  ([{{.ParamsWithoutCtx}}])
+  ([{{.Params}}]))
+`,
+	goHeaderTemplate: `// Auto-generated by gostd2joker at {{.Generated}}, do not edit!!
+
+package {{.Name}}
+
+import ({{range .Imports}}
+	{{.Name}} "{{.Path}}"{{end}}
+)
+`,
+	goFuncTemplate: `
+func {{.Name}}({{.Params}}) {{.ReturnType}} {
+{{.Body}}}
+`,
+}
+
+// Parses the default templates, replacing any found in dir, which
+// (if given) must exist and contain no other files (but hidden ones),
+// lest a misspelt name silently leave the default in place.
+func parseTemplates(dir string) (*template.Template, error) {
+	texts := map[string]string{}
+	for name, text := range defaultTemplates {
+		texts[name] = text
+	}
+	if dir != "" {
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range fis {
+			name := fi.Name()
+			if fi.IsDir() || strings.HasPrefix(name, ".") {
+				continue
+			}
+			if _, ok := defaultTemplates[name]; !ok {
+				return nil, fmt.Errorf("%s is not a template; expected %s", filepath.Join(dir, name), strings.Join(templateNames(), ", "))
+			}
+			by, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			texts[name] = string(by)
+		}
+	}
+	t := template.New("")
+	for name, text := range texts {
+		if _, err := t.New(name).Parse(text); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Returns the names of the templates, in order.
+func templateNames() []string {
+	names := []string{}
+	for name, _ := range defaultTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns the output of the named template, noting the first error (which Run returns).
+func (g *Generator) execute(name string, data interface{}) string {
+	var b strings.Builder
+	if err := g.templates.ExecuteTemplate(&b, name, data); err != nil && g.templateErr == nil {
+		g.templateErr = err
+	}
+	return b.String()
+}
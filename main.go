@@ -1,1532 +1,525 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	. "go/ast"
 	"go/build"
-	"go/parser"
-	"go/token"
 	"io/ioutil"
 	"os"
-	"path"
-	"path/filepath"
 	"regexp"
-	"sort"
-	"strconv"
 	"strings"
-	"time"
-	"unicode"
-)
-
-const VERSION = "0.1"
-
-func check(e error) {
-	if e != nil {
-		panic(e)
-	}
-}
-
-/* Want to support e.g.:
-
-     net/dial.go:DialTimeout(network, address string, timeout time.Duration) => _ Conn, _ error
-
-   I.e. a function defined in one package refers to a type defined in
-   another (a different directory, even).
-
-   Sample routines include (from 'net' package):
-     - lookupMX
-     - queryEscape
-   E.g.:
-     ./gostd2joker --dir $PWD/tests 2>&1 | grep -C20 lookupMX
-
-*/
-
-var fset *token.FileSet
-var dump bool
-var verbose bool
-var methods int
-var generatedFunctions int
-
-func whereAt(p token.Pos) string {
-	return fmt.Sprintf("%s", fset.Position(p).String())
-}
 
-func unix(p string) string {
-	return filepath.ToSlash(p)
-}
-
-func commentGroupInQuotes(doc *CommentGroup, jok, gol string) string {
-	var d string
-	if doc != nil {
-		d = doc.Text()
-	}
-	if gol != "" {
-		if d != "" {
-			d = strings.Trim(d, " \t\n") + "\n\n"
-		}
-		d += "Go return type: " + gol
-	}
-	if jok != "" {
-		if d != "" {
-			d = strings.Trim(d, " \t\n") + "\n\n"
-		}
-		d += "Joker return type: " + jok
-	}
-	return `  ` + strings.Trim(strconv.Quote(d), " \t\n") + "\n"
-}
-
-type funcInfo struct {
-	fd         *FuncDecl
-	pkg        string // base package name
-	pkgDirUnix string // relative (Unix-style) path to package
-	filename   string // relative (Unix-style) filename within package
-}
-
-/* Go apparently doesn't support/allow 'interface{}' as the value (or
-/* key) of a map such that any arbitrary type can be substituted at
-/* run time, so there are several of these nearly-identical functions
-/* sprinkled through this code. Still get some reuse out of some of
-/* them, and it's still easier to maintain these copies than if the
-/* body of these were to be included at each call point.... */
-func sortedFuncInfoMap(m map[string]*funcInfo, f func(k string, v *funcInfo)) {
-	var keys []string
-	for k, _ := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		f(k, m[k])
-	}
-}
+	"github.com/jcburley/gostd2joker/gen"
+)
 
-// Map qualified function names to info on each.
-var qualifiedFunctions = map[string]*funcInfo{}
+/* The generator itself lives in the gen package; this command just
+/* parses a subcommand and its options into gen.Options, runs a
+/* gen.Generator, and writes out (or reports on) the results. */
 
-var alreadySeen = []string{}
+// Exit codes (besides 0, for success).
+const (
+	exitFailure = 1 // Generation failed, or its results couldn't be written
+	exitUsage   = 2 // Invalid command line (including invalid option values)
+	exitParse   = 3 // Go source, config file, templates, or a saved result couldn't be read or parsed
+)
 
-// Returns whether any public functions were actually processed.
-func processFuncDecl(pkg, pkgDirUnix, filename string, f *File, fn *FuncDecl) bool {
-	if dump {
-		Print(fset, fn)
-	}
-	fname := pkgDirUnix + "." + fn.Name.Name
-	if v, ok := qualifiedFunctions[fname]; ok {
-		alreadySeen = append(alreadySeen,
-			fmt.Sprintf("NOTE: Already seen function %s in %s, yet again in %s",
-				fname, v.filename, filename))
-	}
-	qualifiedFunctions[fname] = &funcInfo{fn, pkg, pkgDirUnix, filename}
-	return true
+// An exitError determines the exit code after an error.
+type exitError struct {
+	code int
+	err  error // nil to exit quietly
 }
 
-type typeInfo struct {
-	td       *TypeSpec
-	file     string // Relative (Unix-style) path to defining file
-	building bool
+func (e *exitError) Error() string {
+	return e.err.Error()
 }
 
-func sortedTypeInfoMap(m map[string]*typeInfo, f func(k string, v *typeInfo)) {
-	var keys []string
-	for k, _ := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		f(k, m[k])
-	}
+func usageErrorf(format string, args ...interface{}) error {
+	return &exitError{exitUsage, fmt.Errorf(format, args...)}
 }
 
-var types = map[string]*typeInfo{}
+var errHelpShown = &exitError{0, nil}
 
-func processTypeSpec(pkg string, filename string, f *File, ts *TypeSpec) {
-	if dump {
-		Print(fset, ts)
-	}
-	typename := pkg + "." + ts.Name.Name
-	if c, ok := types[typename]; ok {
-		if c.file == filename {
-			panic(fmt.Sprintf("type %s defined twice in file %s", typename, filename))
-		}
+func exitCode(err error) int {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
 	}
-	types[typename] = &typeInfo{ts, filename, false}
-}
-
-func processTypeSpecs(pkg string, filename string, f *File, tss []Spec) {
-	for _, spec := range tss {
-		ts := spec.(*TypeSpec)
-		if isPrivate(ts.Name.Name) {
-			continue // Skipping non-exported functions
-		}
-		processTypeSpec(pkg, filename, f, ts)
+	var pe *gen.ParseError
+	if errors.As(err, &pe) {
+		return exitParse
 	}
+	return exitFailure
 }
 
-// Returns whether any public functions were actually processed.
-func processDecls(pkg, pkgDirUnix, filename string, f *File) (found bool) {
-	for _, s := range f.Decls {
-		switch v := s.(type) {
-		case *FuncDecl:
-			rcv := v.Recv // *FieldList of methods or nil (functions)
-			if rcv != nil {
-				methods += 1
-				continue // Skipping these for now
-			}
-			if isPrivate(v.Name.Name) {
-				continue // Skipping non-exported functions
-			}
-			if processFuncDecl(pkg, pkgDirUnix, filename, f, v) {
-				found = true
-			}
-		case *GenDecl:
-			if v.Tok != token.TYPE {
-				continue
-			}
-			processTypeSpecs(pkgDirUnix, filename, f, v.Specs)
-		default:
-			panic(fmt.Sprintf("unrecognized Decl type %T at: %s", v, whereAt(v.Pos())))
-		}
-	}
-	return
-}
-
-var exists = struct{}{}
-
-/* Maps relative package (unix-style) names to their imports, non-emptiness, etc. */
-type packageImports map[string]struct{}
-type packageInfo struct {
-	importsNative  packageImports
-	importsAutoGen packageImports
-	nonEmpty       bool // Whether any non-comment code has been generated
-	hasGoFiles     bool // Whether any .go files (would) have been generated
-}
-
-var packagesInfo = map[string]*packageInfo{}
-
-/* Sort the packages -- currently appears to not actually be
-/* necessary, probably because of how walkDirs() works. */
-func sortedPackagesInfo(m map[string]*packageInfo, f func(k string, i *packageInfo)) {
-	var keys []string
-	for k, _ := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		f(k, m[k])
-	}
+type command struct {
+	name    string
+	args    string // Synopsis of its arguments, after any options
+	summary string
+	doc     string // Follows the summary in its usage
+	options string // Its own options, if any, listed before the common ones
+	run     func(args []string) error
 }
 
-func sortedPackageImports(pi packageImports, f func(k string)) {
-	var keys []string
-	for k, _ := range pi {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		f(k)
-	}
-}
+var commands []*command
 
-func processPackage(pkgDir, pkgDirUnix, pkg string, p *Package) {
-	if verbose {
-		fmt.Printf("Processing package=%s in %s:\n", pkg, pkgDirUnix)
-	}
-	found := false
-	for filename, f := range p.Files {
-		if processDecls(pkg, pkgDirUnix, filepath.ToSlash(filename), f) {
-			found = true
-		}
-	}
-	if found {
-		if _, ok := packagesInfo[pkgDirUnix]; !ok {
-			packagesInfo[pkgDirUnix] = &packageInfo{packageImports{}, packageImports{}, false, false}
+func init() {
+	commands = []*command{
+		{"generate", "", "(Default) Generate Joker (and Go) code wrapping a Go source tree", `
+If --joker is not specified (or is "-"), no Go nor Clojure source
+files (nor any other files nor directories) are created, effecting a
+sort of "dry run" that prints the code instead.
+`, `  --joker <joker-source-dir-name>  # Generate into <joker-source-dir-name>/std/go, and modify pertinent source files to refer to it
+  --overwrite                    # Overwrite any existing <joker-std-subdir> files, leaving existing files intact
+  --replace                      # 'rm -fr <joker-std-subdir>' before creating <joker-std-subdir>
+  --fresh                        # (Default) Refuse to overwrite existing <joker-std-subdir> directory
+  --summary                      # Print summary of #s of types, functions, etc.
+`, runGenerate},
+		{"report", "", "Summarize what would be generated, and why functions wouldn't be", `
+With --save, also saves the result, including the Go API found (the
+signatures of its functions, types, methods, constants, and
+variables), for 'gostd2joker diff' to compare to another tree's.
+`, `  --save <file>                  # Save the result (less the generated files) as JSON to <file>
+`, runReport},
+		{"list", "[packages|functions] [<package>...]", "List packages (or functions), and whether they'd be generated", `
+Packages are listed with the numbers of their functions that would be
+generated, out of those found; functions with "generated",
+"excluded", or the ABEND codes explaining why they wouldn't be.
+`, "", runList},
+		{"explain", "[<function>|<ABEND code>...]", "Explain why functions would (or wouldn't) be generated", `
+For a function (e.g. net/url.Parse), prints the code that would be
+generated for it, and the unsupported constructs (if any) preventing
+that, with their ABEND codes and locations; for an ABEND code (e.g.
+ABEND883 or 883), what it means, the Go construct that triggers it,
+whether a config override (or an option) works around it, and (given
+a Go source tree) the functions it affects in this run. With --result,
+explains per a result saved by 'gostd2joker report --save' (e.g. by
+the last run), instead of running the generator. With no arguments,
+lists the ABEND codes.
+`, `  --result <file>                # Explain per the result saved in <file>, rather than running the generator
+`, runExplain},
+		{"unlock", "[<package>...]", "Rank unsupported types by how many functions supporting them would unlock", `
+Attributes each function that wouldn't be generated to the Go types
+blocking it (per the unsupported constructs explaining why), and ranks
+the types by how many functions supporting them would unlock: alone,
+and in combination (adding, at each step, the types unlocking the most
+functions per type added). Does so overall and for each package, or
+just for the given packages.
+`, `  --top <n>                      # Show the top <n> (default 10) types, and steps in combination
+`, runUnlock},
+		{"coverage", "[<package>...]", "Report, per package and type, the API that would (and wouldn't) be wrapped", `
+For each package (or just the given packages), counts the exported
+functions, methods, constants, and variables that would be wrapped,
+out of those found; and lists each, with the reason why one wouldn't
+be. Types are listed with their kinds, their methods, whether they'd
+have a predicate, and how many functions they block (per the
+unsupported constructs). The report is self-contained, so may be
+attached to a wiki page, or compared to that of another run.
+`, `  --format <markdown|json|html>  # Format the report (default markdown), HTML being a single static page
+  --output <file>                # Write the report to <file> (default standard output)
+`, runCoverage},
+		{"diff", "<old-go-source-dir-name|saved-result> <new-go-source-dir-name|saved-result>", "Compare the APIs of, and what would be generated for, two Go source trees", `
+Lists the functions, types, methods, constants, and variables added
+(+), removed (-), or changed (~) in the new tree, with their Go
+signatures (or definitions); then the effects on the generated Joker
+namespaces: functions new to (+) or removed from (-) them, broken (!)
+by changes to their arglists, newly generated (^) or no longer
+generated (v), or whose generated code or ABENDs otherwise changed
+(~). Either tree may instead be a result saved by 'gostd2joker report
+--save', e.g. before upgrading Go. The trees may also be named via
+--go, old first (--go <old> --go <new>).
+`, "", runDiff},
+	}
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
 		}
 	}
+	return nil
 }
 
-func processDir(d string, path string, mode parser.Mode) error {
-	pkgDir := strings.TrimPrefix(path, d+string(filepath.Separator))
-	pkgDirUnix := filepath.ToSlash(pkgDir)
-	if verbose {
-		fmt.Printf("Processing %s:\n", pkgDirUnix)
-	}
-
-	pkgs, err := parser.ParseDir(fset, path,
-		// Walk only *.go files that meet default (target) build constraints, e.g. per "// build ..."
-		func(info os.FileInfo) bool {
-			if strings.HasSuffix(info.Name(), "_test.go") {
-				if verbose {
-					fmt.Printf("Ignoring test code in %s\n", info.Name())
-				}
-				return false
-			}
-			b, e := build.Default.MatchFile(path, info.Name())
-			if verbose {
-				fmt.Printf("Matchfile(%s) => %v %v\n",
-					filepath.ToSlash(filepath.Join(path, info.Name())),
-					b, e)
-			}
-			return b && e == nil
-		},
-		mode)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return err
-	}
+func usage() {
+	fmt.Print(`
+Usage: gostd2joker [<command>] [options...] [args...]
 
-	basename := filepath.Base(path)
-	for k, v := range pkgs {
-		if k != basename && k != basename+"_test" {
-			if verbose {
-				fmt.Printf("NOTICE: Package %s is defined in %s -- ignored\n", k, path)
-			}
-		} else {
-			if verbose {
-				fmt.Printf("Package %s:\n", k)
-			}
-			processPackage(pkgDir, pkgDirUnix, k, v) // processPackage(strings.Replace(path, d + "/", "", 1) + "/" + k, v)
-		}
+Commands:
+`)
+	for _, c := range commands {
+		fmt.Printf("  %-10s %s\n", c.name, c.summary)
 	}
+	fmt.Print(`  help       Print this information, or (given a command) its options
 
-	return nil
-}
+Options may be given as --name value, --name=value, or with a single
+'-'; run 'gostd2joker help <command>' for those of each command.
 
-var excludeDirs = map[string]bool{
-	"builtin":  true,
-	"cmd":      true,
-	"internal": true, // look into this later?
-	"testdata": true,
-	"vendor":   true,
+` + exitCodesDoc)
 }
 
-func walkDirs(d string, mode parser.Mode) error {
-	target, err := filepath.EvalSymlinks(d)
-	check(err)
-	err = filepath.Walk(target,
-		func(path string, info os.FileInfo, err error) error {
-			rel := strings.Replace(path, target, d, 1)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Skipping %s due to: %v\n",
-					filepath.ToSlash(rel), err)
-				return err
-			}
-			if rel == d {
-				return nil // skip (implicit) "."
-			}
-			if excludeDirs[filepath.Base(rel)] {
-				if verbose {
-					fmt.Printf("Excluding %s\n",
-						filepath.ToSlash(rel))
-				}
-				return filepath.SkipDir
-			}
-			if info.IsDir() {
-				if verbose {
-					fmt.Printf("Walking from %s to %s\n",
-						filepath.ToSlash(d), filepath.ToSlash(rel))
-				}
-				return processDir(d, rel, mode)
-			}
-			return nil // not a directory
-		})
-
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error while walking %s: %v\n", d, err)
-		return err
-	}
-
-	return err
-}
+const exitCodesDoc = `Exit codes:
+  0  Success
+  1  Generation failed, or its results couldn't be written
+  2  Invalid command line (unknown command or option, missing or invalid value, etc.)
+  3  Go source, config file, templates, or a saved result couldn't be read or parsed
+`
 
-func exprAsClojure(e Expr) string {
-	switch v := e.(type) {
-	case *Ident:
-		switch v.Name {
-		case "string":
-			return "String"
-		case "int":
-			return "Int"
-		case "byte":
-			return "Byte"
-		case "bool":
-			return "Bool"
-		default:
-			return fmt.Sprintf("ABEND885(unrecognized type %s at: %s)", v.Name, whereAt(e.Pos()))
-		}
-	default:
-		return fmt.Sprintf("ABEND881(unrecognized Expr type %T at: %s)", e, whereAt(e.Pos()))
-	}
-}
+const commonOptionsDoc = `  --go <go-source-dir-name>      # Location of Go source tree's src/ subdirectory (default: per ./GO.link)
+  --verbose, -v                  # Print info on what's going on
+  --empty                        # Generate empty packages (those with no Joker code)
+  --errors-as-exceptions         # Throw, rather than return, trailing non-nil error results
+  --errors-as-exceptions-for <list>  # Same, but only for the comma-separated packages (net/url) and functions (net/url.Parse)
+  --typed-errors                 # Convert errors to exceptions carrying the exported fields of known error types
+  --recover-panics               # Rethrow Go panics in wrapped functions as Joker exceptions
+  --pointer-refs                 # Return pointer results as references to the (live) Go values
+  --round-trip                   # Keep Go values in converted maps' metadata, and accept such maps for struct params
+  --raw-results                  # Return results as Go handles, unconverted, while go.gostd/*raw-results* is true
+  --lazy-slices                  # Return slices as lazy sequences, converting elements only as needed
+  --max-depth <n>                # Return structs nested more than <n> deep as handles instead of maps
+  --out-param <pkg.Func:p[+q]>   # Return the final values of pointer params p (and q) after the results (":" alone disables)
+  --async <list>                 # Call the comma-separated functions (net/http.ListenAndServe) in goroutines, returning futures
+  --config <file>                # Read exclusions and per-function overrides from <file> (default: ./gostd2joker.edn, if any)
+  --templates <dir>              # Use any of the templates (joke-header.tmpl, joke-func.tmpl, go-func.tmpl, etc.) found in <dir>
+  --omit-unsupported             # Leave functions that can't be generated out of the files, rather than commented out there
+  --diagnostics <text|json>      # Print all diagnostics, including why each function can't be generated, in the given format
+  --dump                         # Use go's AST dump API on pertinent elements (functions, types, etc.)
+  --no-timestamp                 # Don't put the time (and version) info in generated/modified files
+  --version, -V                  # Print the version of gostd2joker
+  --help, -h                     # Print this information
+`
 
-func exprAsGo(e Expr) string {
-	switch v := e.(type) {
-	case *Ident:
-		switch v.Name {
-		case "string", "int", "int16", "uint", "uint16", "int32", "uint32", "int64", "byte", "bool", "error":
-			return v.Name
-		default:
-			return fmt.Sprintf("ABEND884(unrecognized type %s at: %s)", v.Name, whereAt(e.Pos()))
-		}
-	default:
-		return fmt.Sprintf("ABEND882(unrecognized Expr type %T at: %s)", e, whereAt(e.Pos()))
+func commandUsage(c *command) {
+	fmt.Printf("\nUsage: gostd2joker %s [options...]", c.name)
+	if c.args != "" {
+		fmt.Printf(" %s", c.args)
 	}
+	fmt.Printf("\n\n%s.\n%s\nOptions:\n%s%s\n%s", c.summary, c.doc, c.options, commonOptionsDoc, exitCodesDoc)
 }
 
-func paramNameAsClojure(n string) string {
-	return n
+// A flag taking a comma-separated list, each use adding to it.
+type listFlag struct {
+	p *[]string
 }
 
-func fieldListAsClojure(fl *FieldList) string {
-	if fl == nil {
+func (f listFlag) String() string {
+	if f.p == nil {
 		return ""
 	}
-	var s string
-	for _, f := range fl.List {
-		cltype := exprAsClojure(f.Type)
-		for _, p := range f.Names {
-			if s != "" {
-				s += ", "
-			}
-			if cltype != "" {
-				s += "^" + cltype + " "
-			}
-			if p == nil {
-				s += "_"
-			} else {
-				s += "_" + paramNameAsClojure(p.Name)
-			}
-		}
-	}
-	return s
-}
-
-func fieldListToGo(fl *FieldList) string {
-	s := ""
-	for _, f := range fl.List {
-		for _, p := range f.Names {
-			if s != "" {
-				s += ", "
-			}
-			if p == nil {
-				s += "ABEND922"
-			} else {
-				s += "_" + p.Name
-			}
-		}
-	}
-	return s
+	return strings.Join(*f.p, ",")
 }
 
-func funcNameAsGoPrivate(f string) string {
-	return strings.ToLower(f[0:1]) + f[1:]
+func (f listFlag) Set(s string) error {
+	*f.p = append(*f.p, strings.Split(s, ",")...)
+	return nil
 }
 
-func paramNameAsGo(p string) string {
-	return p
+// A flag taking a value that may be specified only once.
+type onceFlag struct {
+	p *string
 }
 
-func paramListAsGo(fl *FieldList) string {
-	s := ""
-	for _, f := range fl.List {
-		gotype := exprAsGo(f.Type)
-		for _, p := range f.Names {
-			if s != "" {
-				s += ", "
-			}
-			if p == nil {
-				s += "ABEND712"
-			} else {
-				s += paramNameAsGo(p.Name)
-			}
-			if gotype != "" {
-				s += " " + gotype
-			}
-		}
+func (f onceFlag) String() string {
+	if f.p == nil {
+		return ""
 	}
-	return s
+	return *f.p
 }
 
-func argsAsGo(p *FieldList) string {
-	s := ""
-	for _, f := range p.List {
-		for _, p := range f.Names {
-			if s != "" {
-				s += ", "
-			}
-			if p == nil {
-				s += "ABEND713"
-			} else {
-				s += paramNameAsGo(p.Name)
-			}
-		}
+func (f onceFlag) Set(s string) error {
+	if *f.p != "" {
+		return fmt.Errorf("cannot be specified more than once")
 	}
-	return s
-}
-
-/* The transformation code, below, takes an approach that is new for me.
-
-   Instead of each transformation point having its own transform
-   routine(s), as is customary, I'm trying an approach in which the
-   transform is driven by the input and multiple outputs are
-   generated, where appropriate, for further processing and/or
-   insertion into the ultimate transformation points.
-
-   The primary reason for this is that the input is complicated and
-   (generally) being supported to a greater extent as enhancements are
-   made. I want to maintain coherence among the various transformation
-   insertions, so it's less likely that a change made for one
-   insertion point (to support a new input form, or modify an existing
-   one) won't have corresponding changes made to other forms relying
-   on the same essential input, which could lead to coding errors.
-
-   This approach also should make it easier to see how the different
-   snippets of code, relating to one particular aspect of the input,
-   relate to each other, because the code will be in the same place.
-
-   However, I'm concerned that the resulting code will be too
-   complicated for that to be sufficiently helpful. If I was
-   proficient in a constraint/unification-based transformation
-   language, I'd look at that instead, because it would allow me to
-   express that e.g. "func foo(args) (returns) { ...do things with
-   args...; call foo in some fashion; ...do things with returns... }"
-   not only have specific transformations for each of the variables
-   involved, but that they are also constrained in some fashion
-   (e.g. whatever names are picked for unnamed 'returns' values are
-   the same in both "returns" and "do things with returns"; whatever
-   types are involved in both "args" and "returns" are properly
-   processed in "do things with args" and "do things with returns",
-   respectively; and so on).
-
-   Now that I've refactored the code to achieve this, I'll start
-   adding transformations and see how it goes. Might revert to
-   old-fashioned use of custom transformation code per point (sharing
-   code where appropriate, of course) if it gets too hairy.
-
-*/
-
-var genSymIndex = map[string]int{}
-
-func genSym(pre string) string {
-	var idx int
-	if i, ok := genSymIndex[pre]; ok {
-		idx = i + 1
-	} else {
-		idx = 1
+	if s == "" {
+		return fmt.Errorf("missing value")
 	}
-	genSymIndex[pre] = idx
-	return fmt.Sprintf("%s%d", pre, idx)
+	*f.p = s
+	return nil
 }
 
-func genSymReset() {
-	genSymIndex = map[string]int{}
+// A boolean flag that, when given, sets a shared string to its value
+// (so the last of such flags wins).
+type choiceFlag struct {
+	p *string
+	v string
 }
 
-func exprIsUseful(rtn string) bool {
-	return rtn != "NIL"
+func (f choiceFlag) String() string {
+	return ""
 }
 
-func genGoPostNamed(indent, pkg, in, t, onlyIf string) (jok, gol, goc, out string) {
-	qt := pkg + "." + t
-	if v, ok := types[qt]; ok {
-		if v.building { // Mutually-referring types currently not supported
-			jok = fmt.Sprintf("ABEND947(recursive type reference involving %s)",
-				qt) // TODO: handle these, e.g. http Request/Response
-			gol = jok
-			goc = ""
-		} else {
-			v.building = true
-			jok, gol, goc, out = genGoPostExpr(indent, pkg, in, v.td.Type, onlyIf)
-			v.building = false
-		}
-	} else {
-		jok = fmt.Sprintf("ABEND042(cannot find typename %s)", qt)
+func (f choiceFlag) Set(s string) error {
+	if s != "true" {
+		return fmt.Errorf("takes no value")
 	}
-	return
+	*f.p = f.v
+	return nil
 }
 
-func isPrivate(p string) bool {
-	return !unicode.IsUpper(rune(p[0]))
+func (f choiceFlag) IsBoolFlag() bool {
+	return true
 }
 
-// func tryThis(s string) struct { a int; b string } {
-//	return struct { a int; b string }{ 5, "hey" }
-// }
-
-// Joker: { :a ^Int, :b ^String }
-// Go: struct { a int; b string }
-func genGoPostStruct(indent, pkg, in string, fl *FieldList, onlyIf string) (jok, gol, goc, out string) {
-	tmpmap := "_map" + genSym("")
-	useful := false
-	for _, f := range fl.List {
-		for _, p := range f.Names {
-			if isPrivate(p.Name) {
-				continue // Skipping non-exported fields
-			}
-			var joktype, goltype, more_goc string
-			joktype, goltype, more_goc, out =
-				genGoPostExpr(indent, pkg, in+"."+p.Name, f.Type, "")
-			if useful || exprIsUseful(out) {
-				useful = true
-			}
-			goc += more_goc
-			goc += indent + tmpmap +
-				".Add(MakeKeyword(\"" + p.Name + "\"), " + out + ")\n"
-			if jok != "" {
-				jok += ", "
-			}
-			if gol != "" {
-				gol += "; "
-			}
-			if p == nil {
-				jok += "_ "
-			} else {
-				jok += ":" + p.Name + " "
-				gol += p.Name + " "
-			}
-			if joktype != "" {
-				jok += "^" + joktype
-			}
-			if goltype != "" {
-				gol += goltype
+// Options common to the commands that run the generator.
+type options struct {
+	gen.Options
+	configFile  string
+	diagnostics string // "text" or "json", if all diagnostics are to be printed (in that format)
+	version     bool
+}
+
+func newFlagSet(c *command, o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard) // Errors are reported by main
+	fs.Usage = func() {}
+	fs.Var(onceFlag{&o.GoDir}, "go", "")
+	fs.BoolVar(&o.Verbose, "verbose", false, "")
+	fs.BoolVar(&o.Verbose, "v", false, "")
+	fs.BoolVar(&o.GenerateEmpty, "empty", false, "")
+	fs.BoolVar(&o.ErrorsAsExceptions, "errors-as-exceptions", false, "")
+	fs.Var(listFlag{&o.ErrorsAsExceptionsFor}, "errors-as-exceptions-for", "")
+	fs.BoolVar(&o.TypedErrors, "typed-errors", false, "")
+	fs.BoolVar(&o.RecoverPanics, "recover-panics", false, "")
+	fs.BoolVar(&o.PointerRefs, "pointer-refs", false, "")
+	fs.BoolVar(&o.RoundTrip, "round-trip", false, "")
+	fs.BoolVar(&o.RawResults, "raw-results", false, "")
+	fs.BoolVar(&o.LazySlices, "lazy-slices", false, "")
+	fs.IntVar(&o.MaxDepth, "max-depth", 0, "")
+	fs.Var(listFlag{&o.OutParams}, "out-param", "")
+	fs.Var(listFlag{&o.Async}, "async", "")
+	fs.Var(onceFlag{&o.configFile}, "config", "")
+	fs.Var(onceFlag{&o.TemplateDir}, "templates", "")
+	fs.BoolVar(&o.OmitUnsupported, "omit-unsupported", false, "")
+	fs.Var(onceFlag{&o.diagnostics}, "diagnostics", "")
+	fs.BoolVar(&o.Dump, "dump", false, "")
+	fs.BoolVar(&o.NoTimestamp, "no-timestamp", false, "")
+	fs.BoolVar(&o.version, "version", false, "")
+	fs.BoolVar(&o.version, "V", false, "")
+	return fs
+}
+
+var flagNameRegexp = regexp.MustCompile(`(^|\s)-([[:alpha:]])`)
+
+// Parses args per fs, returning the remaining (non-option) args,
+// which may be interspersed with the options (until "--").
+func parseFlags(c *command, fs *flag.FlagSet, o *options, args []string) ([]string, error) {
+	rest := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				commandUsage(c)
+				return nil, errHelpShown
 			}
+			msg := strings.Replace(err.Error(), "flag provided but not defined", "unrecognized option", 1)
+			msg = strings.Replace(msg, "flag", "option", -1)
+			return nil, usageErrorf("%s", flagNameRegexp.ReplaceAllString(msg, "$1--$2"))
 		}
-	}
-	jok = "{" + jok + "}"
-	gol = "struct {" + gol + "}"
-	if useful {
-		goc = wrapStmtOnlyIfs(indent, tmpmap, "ArrayMap", "EmptyArrayMap()", onlyIf, goc, &out)
-	} else {
-		goc = ""
-		out = "NIL"
-	}
-	return
-}
-
-func genGoPostArray(indent, pkg, in string, el Expr, onlyIf string) (jok, gol, goc, out string) {
-	tmp := genSym("")
-	tmpvec := "_vec" + tmp
-	tmpelem := "_elem" + tmp
-
-	var goc_pre string
-	jok, gol, goc_pre, out = genGoPostExpr(indent+"\t", pkg, tmpelem, el, "")
-	useful := exprIsUseful(out)
-	jok = "(vector-of " + jok + ")"
-	gol = "[]" + gol
-
-	if useful {
-		goc = indent + "for _, " + tmpelem + " := range " + in + " {\n"
-		goc += goc_pre
-		goc += indent + "\t" + tmpvec + " = " + tmpvec + ".Conjoin(" + out + ")\n"
-		goc += indent + "}\n"
-		goc = wrapStmtOnlyIfs(indent, tmpvec, "Vector", "EmptyVector", onlyIf, goc, &out)
-	} else {
-		goc = ""
-	}
-	return
-}
-
-// TODO: Maybe return a ref or something Joker (someday) supports? flag.String() is useful only as it returns a ref;
-// whereas net.LookupMX() returns []*MX, and these are not only populated, it's unclear there's any utility in
-// modifying them (it could just as well return []MX AFAICT).
-func genGoPostStar(indent, pkg, in string, e Expr, onlyIf string) (jok, gol, goc, out string) {
-	if onlyIf == "" {
-		onlyIf = in + " != nil"
-	} else {
-		onlyIf = in + " != nil && " + onlyIf
-	}
-	jok, gol, goc, out = genGoPostExpr(indent, pkg, "(*"+in+")", e, onlyIf)
-	gol = "*" + gol
-	return
-}
-
-func maybeNil(expr, in string) string {
-	return "func () Object { if (" + expr + ") == nil { return NIL } else { return " + in + " } }()"
-}
-
-func genGoPostExpr(indent, pkg, in string, e Expr, onlyIf string) (jok, gol, goc, out string) {
-	switch v := e.(type) {
-	case *Ident:
-		switch v.Name {
-		case "string":
-			jok = "String"
-			gol = "string"
-			out = "MakeString(" + in + ")"
-		case "int", "int16", "uint", "uint16", "int32", "uint32", "int64", "byte": // TODO: Does Joker always have 64-bit signed ints?
-			jok = "Int"
-			gol = "int"
-			out = "MakeInt(int(" + in + "))"
-		case "bool":
-			jok = "Bool"
-			gol = "bool"
-			out = "MakeBool(" + in + ")"
-		case "error":
-			jok = "Error"
-			gol = "error"
-			out = maybeNil(in, "MakeError("+in+")") // TODO: Test this against the MakeError() added to joker/core/object.go
-		default:
-			jok, _, goc, out = genGoPostNamed(indent, pkg, in, v.Name, onlyIf)
-			gol = v.Name // This is as far as Go needs to go for a type signature
+		remaining := fs.Args()
+		if len(remaining) == 0 {
+			break
 		}
-	case *ArrayType:
-		jok, gol, goc, out = genGoPostArray(indent, pkg, in, v.Elt, onlyIf)
-	case *StarExpr:
-		jok, gol, goc, out = genGoPostStar(indent, pkg, in, v.X, onlyIf)
-	case *StructType:
-		jok, gol, goc, out = genGoPostStruct(indent, pkg, in, v.Fields, onlyIf)
-	default:
-		jok = fmt.Sprintf("ABEND883(unrecognized Expr type %T at: %s)", e, unix(whereAt(e.Pos())))
-		gol = "..."
-		out = in
-	}
-	return
-}
-
-const resultName = "_res"
-
-func genGoPostItem(indent, pkg, in string, f *Field, onlyIf string) (captureVar, jok, gol, goc, out string, useful bool) {
-	captureVar = in
-	if in == "" {
-		captureVar = genSym(resultName)
+		if i := len(args) - len(remaining); i > 0 && args[i-1] == "--" {
+			rest = append(rest, remaining...)
+			break
+		}
+		rest = append(rest, remaining[0])
+		args = remaining[1:]
 	}
-	jok, gol, goc, out = genGoPostExpr(indent, pkg, captureVar, f.Type, onlyIf)
-	if in != "" && in != resultName {
-		gol = paramNameAsGo(in) + " " + gol
+	if o.version {
+		fmt.Printf("gostd2joker version %s\n", gen.VERSION)
+		return nil, errHelpShown
 	}
-	useful = exprIsUseful(out)
-	if !useful {
-		captureVar = "_"
+	if o.diagnostics != "" && o.diagnostics != "text" && o.diagnostics != "json" {
+		return nil, usageErrorf("invalid value %q for --diagnostics: expected text or json", o.diagnostics)
 	}
-	return
+	return rest, nil
 }
 
-func reverseJoin(a []string, infix string) string {
-	j := ""
-	for idx := len(a) - 1; idx >= 0; idx-- {
-		if idx != len(a)-1 {
-			j += infix
-		}
-		j += a[idx]
-	}
-	return j
-}
+const defaultConfig = "gostd2joker.edn"
 
-// Generates code that, at run time, tests each of the onlyIf's and, if all true, returns the expr; else returns NIL.
-func wrapOnlyIfs(onlyIf string, e string) string {
-	if len(onlyIf) == 0 {
-		return e
+// Finds the Go source tree (if not specified) and reads the config file.
+func (o *options) setUp() error {
+	if o.Verbose {
+		fmt.Printf("Default context: %v\n", build.Default)
 	}
-	return "func() Object { if " + onlyIf + " { return " + e + " } else { return NIL } }()"
-}
-
-// Add one level of indent to each line
-func indentedCode(c string) string {
-	return "\t" + strings.Replace(c, "\n", "\n\t", -1)
-}
 
-func wrapStmtOnlyIfs(indent, v, t, e string, onlyIf string, c string, out *string) string {
-	if len(onlyIf) == 0 {
-		*out = v
-		return indent + v + " := " + e + "\n" + c
-	}
-	*out = "_obj" + v
-	return indent + "var " + *out + " Object\n" +
-		indent + "if " + onlyIf + " {\n" +
-		indent + "\t" + v + " := " + e + "\n" +
-		strings.TrimRight(indentedCode(c), "\t") +
-		indent + "\t" + *out + " = Object(" + v + ")\n" +
-		indent + "} else {\n" +
-		indent + "\t" + *out + " = NIL\n" +
-		indent + "}\n"
-}
-
-// Caller generates "outGOCALL;goc" while saving jok and gol for type info (they go into .joke as metadata and docstrings)
-func genGoPostList(indent string, pkg string, fl FieldList) (jok, gol, goc, out string) {
-	useful := false
-	captureVars := []string{}
-	jokType := []string{}
-	golType := []string{}
-	goCode := []string{}
-
-	result := resultName
-	multipleCaptures := len(fl.List) > 1 || (fl.List[0].Names != nil && len(fl.List[0].Names) > 1)
-	for _, f := range fl.List {
-		names := []string{}
-		if f.Names == nil {
-			names = append(names, "")
-		} else {
-			for _, n := range f.Names {
-				names = append(names, n.Name)
-			}
-		}
-		for _, n := range names {
-			captureName := result
-			if multipleCaptures {
-				captureName = n
+	if o.GoDir == "" {
+		goLink := "GO.link"
+		si, e := os.Stat(goLink)
+		if e == nil && !si.IsDir() {
+			var by []byte
+			by, e = ioutil.ReadFile(goLink)
+			if e != nil {
+				return usageErrorf("must specify --go <go-source-dir-name> option, or put <go-source-dir-name> as the first line of a file named ./GO.link")
 			}
-			captureVar, jok, gol, goc, out, usefulItem := genGoPostItem(indent, pkg, captureName, f, "")
-			useful = useful || usefulItem
-			if multipleCaptures {
-				goc += indent + result + " = " + result + ".Conjoin(" + out + ")\n"
+			m := string(by)
+			if idx := strings.IndexAny(m, "\r\n"); idx == -1 {
+				goLink = m
 			} else {
-				result = out
+				goLink = m[0:idx]
 			}
-			captureVars = append(captureVars, captureVar)
-			jokType = append(jokType, jok)
-			golType = append(golType, gol)
-			goCode = append(goCode, goc)
+			si, e = os.Stat(goLink)
 		}
+		if e != nil || !si.IsDir() {
+			return usageErrorf("must specify --go <go-source-dir-name> option, or make %s a symlink (or text file containing the native path) pointing to the golang/go/ source directory", goLink)
+		}
+		o.GoDir = goLink
 	}
 
-	out = strings.Join(captureVars, ", ")
-	if out != "" {
-		out += " := "
-	}
-
-	jok = strings.Join(jokType, " ")
-	if len(jokType) > 1 && jok != "" {
-		jok = "[" + jok + "]"
-	}
-
-	gol = strings.Join(golType, ", ")
-	if len(golType) > 1 && gol != "" {
-		gol = "(" + gol + ")"
-	}
-
-	goc = strings.Join(goCode, "")
-
-	if multipleCaptures {
-		if useful {
-			goc = indent + result + " := EmptyVector\n" + goc + indent + "return " + result + "\n"
-		} else {
-			goc = indent + "ABEND123(no public information returned)\n"
+	if o.configFile == "" {
+		if si, e := os.Stat(defaultConfig); e == nil && !si.IsDir() {
+			o.configFile = defaultConfig // Alongside GO.link, if that's used
 		}
-	} else {
-		if goc == "" {
-			out = "return " // No code generated, so no need to use intermediary
-		} else {
-			goc += indent + "return " + result + "\n"
+	}
+	if o.configFile != "" {
+		if o.Verbose {
+			fmt.Printf("Reading config from %s\n", o.configFile)
 		}
-		if !useful {
-			goc += indent + "ABEND124(no public information returned)\n"
+		c, err := gen.LoadConfig(o.configFile)
+		if err != nil {
+			return err
 		}
+		o.Config = c
 	}
-
-	return
-}
-
-// Return a form of the return type as supported by generate-std.joke,
-// or empty string if not supported (which will trigger attempting to
-// generate appropriate code for *_native.go). gol either passes
-// through or "Object" is returned for it if jok is returned as empty.
-func jokerReturnTypeForGenerateSTD(in_jok, in_gol string) (jok, gol string) {
-	switch in_jok {
-	case "String", "Int", "Byte", "Double", "Bool", "Time", "Error": // TODO: Have tested only String so far
-		jok = `^"` + in_jok + `"`
-	default:
-		jok = ""
-		gol = "Object"
-	}
-	return
-}
-
-type codeInfo map[string]string
-
-/* Map relative (Unix-style) package names to maps of filenames to code strings. */
-var jokerCode = map[string]codeInfo{}
-var goCode = map[string]codeInfo{}
-
-func sortedPackageMap(m map[string]codeInfo, f func(k string, v codeInfo)) {
-	var keys []string
-	for k, _ := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		f(k, m[k])
-	}
-}
-
-func sortedCodeMap(m codeInfo, f func(k string, v string)) {
-	var keys []string
-	for k, _ := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		f(k, m[k])
-	}
-}
-
-var nonEmptyLineRegexp *regexp.Regexp
-
-type funcCode struct {
-	jokerParamList        string // fieldListAsClojure(d.Type.Params)
-	goParamList           string // paramListAsGo(d.Type.Params)
-	jokerGoParams         string // "(" + fieldListToGo(d.Type.Params) + ")"
-	goCode                string
-	jokerReturnTypeForDoc string // genReturnType(pkg, d.Type.Results)
-	goReturnTypeForDoc    string // genReturnType(pkg, d.Type.Results)
-}
-
-func genGoPre(indent string, fl *FieldList, goFname string) (jok, jok2golParams, gol, code, params string) {
-	jok = fieldListAsClojure(fl)
-	jok2golParams = "(" + fieldListToGo(fl) + ")"
-	code = "" // TODO: enhance to support composites
-	gol = paramListAsGo(fl)
-	params = argsAsGo(fl)
-	return
-}
-
-func genGoCall(pkg, goFname string, goParams string) string {
-	return "_" + pkg + "." + goFname + "(" + goParams + ")\n"
-}
-
-func genGoPost(indent string, pkg string, d *FuncDecl) (goResultAssign, jokerReturnTypeForDoc, goReturnTypeForDoc string, goReturnCode string) {
-	fl := d.Type.Results
-	if fl == nil || fl.List == nil {
-		return
-	}
-	jokerReturnTypeForDoc, goReturnTypeForDoc, goReturnCode, goResultAssign = genGoPostList(indent, pkg, *fl)
-	return
+	return nil
 }
 
-func genFuncCode(pkgBaseName, pkgDirUnix string, d *FuncDecl, goFname string) (fc funcCode) {
-	var goPreCode, goParams, goResultAssign, goPostCode string
-
-	fc.jokerParamList, fc.jokerGoParams, fc.goParamList, goPreCode, goParams =
-		genGoPre("\t", d.Type.Params, goFname)
-	goCall := genGoCall(pkgBaseName, d.Name.Name, goParams)
-	goResultAssign, fc.jokerReturnTypeForDoc, fc.goReturnTypeForDoc, goPostCode =
-		genGoPost("\t", pkgDirUnix, d)
-
-	if goPostCode == "" && goResultAssign == "" {
-		goPostCode = "\t...ABEND675: TODO...\n"
+// Runs the generator, printing its diagnostics.
+func (o *options) run() (*gen.Result, error) {
+	g, err := gen.New(o.Options)
+	if err != nil {
+		var pe *gen.ParseError
+		if errors.As(err, &pe) {
+			return nil, err
+		}
+		return nil, &exitError{exitUsage, err} // An invalid option
 	}
-
-	fc.goCode = goPreCode + // Optional block of pre-code
-		"\t" + goResultAssign + goCall + // [results := ]fn-to-call([args...])
-		goPostCode // Optional block of post-code
-	return
-}
-
-// If the Go API returns a single result, and it's an Int, wrap the call in "int()". If a StarExpr is found, ABEND for now
-// TODO: Return ref's for StarExpr?
-func maybeConvertGoResult(pkg, call string, fl *FieldList) string {
-	if fl == nil || len(fl.List) != 1 || (fl.List[0].Names != nil && len(fl.List[0].Names) > 1) {
-		return call
+	res, err := g.Run()
+	if err != nil {
+		return nil, err
 	}
-	named := false
-	t := fl.List[0].Type
-	for {
-		stop := false
-		switch v := t.(type) {
-		case *Ident:
-			qt := pkg + "." + v.Name
-			if v, ok := types[qt]; ok {
-				named = true
-				t = v.td.Type
-			} else {
-				stop = true
+	switch o.diagnostics {
+	case "":
+		for _, d := range res.Diagnostics {
+			if d.Severity != gen.Unsupported { // Those are shown by the ABENDs in the code, and summarized
+				fmt.Fprintln(os.Stderr, diagnosticText(d))
 			}
-		default:
-			stop = true
 		}
-		if stop {
-			break
+	case "text":
+		for _, d := range res.Diagnostics {
+			fmt.Fprintln(os.Stderr, diagnosticLine(d))
 		}
-	}
-	switch v := t.(type) {
-	case *Ident:
-		switch v.Name {
-		case "int16", "uint", "uint16", "int32", "uint32", "int64", "byte": // TODO: Does Joker always have 64-bit signed ints?
-			return "int(" + call + ")"
-		case "int":
-			if named {
-				return "int(" + call + ")"
-			} // Else it's already an int, so don't bother wrapping it.
+	case "json":
+		ds := res.Diagnostics
+		if ds == nil {
+			ds = []gen.Diagnostic{}
 		}
-	case *StarExpr:
-		return fmt.Sprintf("ABEND401(StarExpr not supported -- no refs returned just yet: %s)", call)
-	}
-	return call
-}
-
-var abendRegexp *regexp.Regexp
-
-var abends = map[string]int{}
-
-func trackAbends(a string) {
-	subMatches := abendRegexp.FindAllStringSubmatch(a, -1)
-	//	fmt.Printf("trackAbends: %v %s => %#v\n", abendRegexp, a, subMatches)
-	for _, m := range subMatches {
-		if len(m) != 2 {
-			panic(fmt.Sprintf("len(%v) != 2", m))
-		}
-		n := m[1]
-		if _, ok := abends[n]; !ok {
-			abends[n] = 0
+		by, err := json.MarshalIndent(ds, "", "  ")
+		if err != nil {
+			return nil, err
 		}
-		abends[n] += 1
+		fmt.Fprintln(os.Stderr, string(by))
 	}
+	return res, nil
 }
 
-func printAbends(m map[string]int) {
-	type ac struct {
-		abendCode  string
-		abendCount int
-	}
-	a := []ac{}
-	for k, v := range m {
-		a = append(a, ac{abendCode: k, abendCount: v})
-	}
-	sort.Slice(a,
-		func(i, j int) bool {
-			if a[i].abendCount == a[j].abendCount {
-				return a[i].abendCode < a[j].abendCode
-			}
-			return a[i].abendCount > a[j].abendCount
-		})
-	for _, v := range a {
-		fmt.Printf(" %s(%d)", v.abendCode, v.abendCount)
+func diagnosticText(d gen.Diagnostic) string {
+	if d.Severity == gen.Note {
+		return "NOTE: " + d.Message
 	}
+	return d.Message
 }
 
-func genFunction(f string, fn *funcInfo) {
-	genSymReset()
-	d := fn.fd
-	pkgDirUnix := fn.pkgDirUnix
-	pkgBaseName := filepath.Base(pkgDirUnix)
-	jfmt := `
-(defn %s%s
-%s  {:added "1.0"
-   :go "%s"}
-  [%s])
-`
-	goFname := funcNameAsGoPrivate(d.Name.Name)
-	fc := genFuncCode(pkgBaseName, pkgDirUnix, d, goFname)
-	jokerReturnType, goReturnType := jokerReturnTypeForGenerateSTD(fc.jokerReturnTypeForDoc, fc.goReturnTypeForDoc)
-
-	var jok2gol string
-	if jokerReturnType == "" {
-		jok2gol = goFname
-	} else {
-		jokerReturnType += " "
-		jok2gol = pkgBaseName + "." + d.Name.Name
-		if _, found := packagesInfo[pkgDirUnix]; !found {
-			panic(fmt.Sprintf("Cannot find package %s", pkgDirUnix))
-		}
-	}
-	jok2golCall := maybeConvertGoResult(pkgDirUnix, jok2gol+fc.jokerGoParams, fn.fd.Type.Results)
-
-	jokerFn := fmt.Sprintf(jfmt, jokerReturnType, d.Name.Name,
-		commentGroupInQuotes(d.Doc, fc.jokerReturnTypeForDoc, fc.goReturnTypeForDoc),
-		jok2golCall, fc.jokerParamList)
-
-	gfmt := `
-func %s(%s) %s {
-%s}
-`
-
-	goFn := ""
-	if jokerReturnType == "" { // TODO: Generate this anyway if it contains ABEND, so we can see what's needed.
-		goFn = fmt.Sprintf(gfmt, goFname, fc.goParamList, goReturnType, fc.goCode)
-	}
-
-	if strings.Contains(jokerFn, "ABEND") || strings.Contains(goFn, "ABEND") {
-		jokerFn = nonEmptyLineRegexp.ReplaceAllString(jokerFn, `;; $1`)
-		goFn = nonEmptyLineRegexp.ReplaceAllString(goFn, `// $1`)
-		trackAbends(jokerFn)
-		trackAbends(goFn)
-	} else {
-		generatedFunctions++
-		packagesInfo[pkgDirUnix].nonEmpty = true
-		if jokerReturnType == "" {
-			packagesInfo[pkgDirUnix].importsNative[pkgDirUnix] = exists
-		} else {
-			packagesInfo[pkgDirUnix].importsAutoGen[pkgDirUnix] = exists
+// Formats d as "file:line:col: severity: message", noting any ABEND
+// code, type, and function involved.
+func diagnosticLine(d gen.Diagnostic) string {
+	s := ""
+	if d.File != "" {
+		s = d.File
+		if d.Line != 0 {
+			s += fmt.Sprintf(":%d:%d", d.Line, d.Column)
 		}
+		s += ": "
 	}
-
-	if _, ok := jokerCode[pkgDirUnix]; !ok {
-		jokerCode[pkgDirUnix] = codeInfo{}
-	}
-	jokerCode[pkgDirUnix][d.Name.Name] = jokerFn
-
-	if _, ok := goCode[pkgDirUnix]; !ok {
-		goCode[pkgDirUnix] = codeInfo{} // There'll at least be a .joke file
-	}
-	if goFn != "" {
-		goCode[pkgDirUnix][d.Name.Name] = goFn
+	s += string(d.Severity) + ": "
+	if d.Code != "" {
+		s += "ABEND" + d.Code + ": "
 	}
-}
-
-func notOption(arg string) bool {
-	return arg == "-" || !strings.HasPrefix(arg, "-")
-}
-
-func usage() {
-	fmt.Print(`
-Usage: gostd2joker options...
-
-Options:
-  --go <go-source-dir-name>      # Location of Go source tree's src/ subdirectory
-  --overwrite                    # Overwrite any existing <joker-std-subdir> files, leaving existing files intact
-  --replace                      # 'rm -fr <joker-std-subdir>' before creating <joker-std-subdir>
-  --fresh                        # (Default) Refuse to overwrite existing <joker-std-subdir> directory
-  --joker <joker-source-dir-name>  # Modify pertinent source files to reflect packages being created
-  --verbose, -v                  # Print info on what's going on
-  --summary                      # Print summary of #s of types, functions, etc.
-  --empty                        # Generate empty packages (those with no Joker code)
-  --dump                         # Use go's AST dump API on pertinent elements (functions, types, etc.)
-  --no-timestamp                 # Don't put the time (and version) info in generated/modified files
-  --help, -h                     # Print this information
-
-If <joker-std-subdir> is not specified, no Go nor Clojure source files
-(nor any other files nor directories) are created, effecting a sort of
-"dry run".
-`)
-	os.Exit(0)
-}
-
-var currentTimeAndVersion = ""
-var noTimeAndVersion = false
-
-func curTimeAndVersion() string {
-	if noTimeAndVersion {
-		return "(omitted for testing)"
+	s += d.Message
+	if d.Type != "" {
+		s += " (type " + d.Type + ")"
 	}
-	if currentTimeAndVersion == "" {
-		by, _ := time.Now().MarshalText()
-		currentTimeAndVersion = string(by) + " by version " + VERSION
+	if d.Function != "" {
+		s += " in " + d.Function
 	}
-	return currentTimeAndVersion
+	return s
 }
 
-// E.g.: \t_ "github.com/candid82/joker/std/go/net"
-func updateJokerMain(pkgs []string, f string) {
-	by, err := ioutil.ReadFile(f)
-	check(err)
-	m := string(by)
-	flag := "Imports added by gostd2joker"
-	endflag := "End gostd2joker-added imports"
-
-	if !strings.Contains(m, flag) {
-		if verbose {
-			fmt.Printf("Adding custom import line to %s\n", filepath.ToSlash(f))
-		}
-		m = strings.Replace(m, "import", "import ( // "+flag+"\n) // "+endflag+"\n\nimport", 1)
-		m = "// Auto-modified by gostd2joker at " + curTimeAndVersion() + "\n\n" + m
-	}
+var verbose bool // Whether to show a stack trace for an internal error
 
-	reImport := regexp.MustCompile("(?msU)" + flag + ".*" + endflag) // [^(]*[(][^)]*[)]
-	newImports := "\n"
-	importPrefix := "\t_ \"github.com/candid82/joker/std/go/"
-	for _, p := range pkgs {
-		newImports += importPrefix + p + "\"\n"
-	}
-	m = reImport.ReplaceAllString(m, flag+newImports+") // "+endflag)
-
-	if verbose {
-		fmt.Printf("Writing %s\n", filepath.ToSlash(f))
+func main() {
+	args := os.Args[1:]
+	name := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
-	err = ioutil.WriteFile(f, []byte(m), 0777)
-	check(err)
-}
-
-// E.g.: *loaded-libs* #{'joker.core 'joker.os 'joker.base64 'joker.json 'joker.string 'joker.yaml 'joker.go.net})
-func updateCoreDotJoke(pkgs []string, f string) {
-	by, err := ioutil.ReadFile(f)
-	check(err)
-	m := string(by)
-	flag := "Loaded-libraries added by gostd2joker"
-	endflag := "End gostd2joker-added loaded-libraries"
-
-	if !strings.Contains(m, flag) {
-		if verbose {
-			fmt.Printf("Adding custom loaded libraries to %s\n", filepath.ToSlash(f))
+	for _, a := range args {
+		if a == "-v" || a == "--verbose" {
+			verbose = true
 		}
-		m = strings.Replace(m, "\n  *loaded-libs* #{",
-			"\n  *loaded-libs* #{\n   ;; "+flag+"\n   ;; "+endflag+"\n", 1)
-		m = ";;;; Auto-modified by gostd2joker at " + curTimeAndVersion() + "\n\n" + m
 	}
 
-	reImport := regexp.MustCompile("(?msU)" + flag + ".*" + endflag + "\n *?")
-	newImports := "\n  "
-	importPrefix := " 'joker.go."
-	curLine := ""
-	for _, p := range pkgs {
-		more := importPrefix + strings.Replace(p, "/", ".", -1)
-		if curLine != "" && len(curLine)+len(more) > 77 {
-			newImports += curLine + "\n  "
-			curLine = more
-		} else {
-			curLine += more
+	var c *command
+	switch name {
+	case "help":
+		if len(args) == 0 {
+			usage()
+			os.Exit(0)
 		}
-	}
-	newImports += curLine
-	m = reImport.ReplaceAllString(m, flag+newImports+"\n   ;; "+endflag+"\n   ")
-
-	if verbose {
-		fmt.Printf("Writing %s\n", filepath.ToSlash(f))
-	}
-	err = ioutil.WriteFile(f, []byte(m), 0777)
-	check(err)
-}
-
-// E.g.: (def namespaces ['string 'json 'base64 'os 'time 'yaml 'http 'math 'html 'url])
-func updateGenerateSTD(pkgs []string, f string) {
-	by, err := ioutil.ReadFile(f)
-	check(err)
-	m := string(by)
-	flag := "Namespaces added by gostd2joker"
-	endflag := "End gostd2joker-added namespaces"
-
-	if !strings.Contains(m, flag) {
-		if verbose {
-			fmt.Printf("Adding custom namespaces to %s\n", filepath.ToSlash(f))
+		if c = findCommand(args[0]); c == nil {
+			fail(name, usageErrorf("unknown command %q", args[0]))
 		}
-		m = strings.Replace(m, "(def namespaces [",
-			"(def namespaces [\n  ;; "+flag+"\n  ;; "+endflag+"\n  ", 1)
-		m = ";;;; Auto-modified by gostd2joker at " + curTimeAndVersion() + "\n\n" + m
-	}
-
-	reImport := regexp.MustCompile("(?msU)" + flag + ".*" + endflag + "\n *?")
-	newImports := "\n "
-	importPrefix := " 'go."
-	curLine := ""
-	for _, p := range pkgs {
-		more := importPrefix + strings.Replace(p, "/", ".", -1)
-		if curLine != "" && len(curLine)+len(more) > 77 {
-			newImports += curLine + "\n "
-			curLine = more
-		} else {
-			curLine += more
+		commandUsage(c)
+		os.Exit(0)
+	default:
+		if c = findCommand(name); c == nil {
+			fail("", usageErrorf("unknown command %q", name))
 		}
 	}
-	newImports += curLine
-	m = reImport.ReplaceAllString(m, flag+newImports+"\n  ;; "+endflag+"\n  ")
-
-	if verbose {
-		fmt.Printf("Writing %s\n", filepath.ToSlash(f))
-	}
-	err = ioutil.WriteFile(f, []byte(m), 0777)
-	check(err)
-}
 
-func packageQuotedImportList(pi packageImports, prefix string, rename bool) string {
-	imports := ""
-	sortedPackageImports(pi,
-		func(k string) {
-			if rename {
-				imports += prefix + "_" + path.Base(k) + ` "` + k + `"`
-			} else {
-				imports += prefix + `"` + k + `"`
-			}
-		})
-	return imports
-}
-
-func main() {
-	fset = token.NewFileSet() // positions are relative to fset
-	dump = false
-
-	length := len(os.Args)
-	sourceDir := ""
-	jokerSourceDir := ""
-	replace := false
-	overwrite := false
-	summary := false
-	generateEmpty := false
-
-	var mode parser.Mode = parser.ParseComments
-
-	for i := 1; i < length; i++ { // shift
-		a := os.Args[i]
-		if a[0] == "-"[0] {
-			switch a {
-			case "--help", "-h":
-				usage()
-			case "--version", "-V":
-				fmt.Printf("%s version %s\n", os.Args[0], VERSION)
-			case "--no-timestamp":
-				noTimeAndVersion = true
-			case "--dump":
-				dump = true
-			case "--overwrite":
-				overwrite = true
-				replace = false
-			case "--replace":
-				replace = true
-				overwrite = false
-			case "--fresh":
-				replace = false
-				overwrite = false
-			case "--verbose", "-v":
-				verbose = true
-			case "--summary":
-				summary = true
-			case "--empty":
-				generateEmpty = true
-			case "--go":
-				if sourceDir != "" {
-					panic("cannot specify --go <go-source-dir-name> more than once")
-				}
-				if i < length-1 && notOption(os.Args[i+1]) {
-					i += 1 // shift
-					sourceDir = os.Args[i]
-				} else {
-					panic("missing path after --go option")
-				}
-			case "--joker":
-				if jokerSourceDir != "" {
-					panic("cannot specify --joker <joker-source-dir-name> more than once")
-				}
-				if i < length-1 && notOption(os.Args[i+1]) {
-					i += 1 // shift
-					jokerSourceDir = os.Args[i]
-				} else {
-					panic("missing path after --joker option")
-				}
-			default:
-				panic("unrecognized option " + a)
+	defer func() {
+		if r := recover(); r != nil {
+			if verbose {
+				panic(r)
 			}
-		} else {
-			panic("extraneous argument(s) starting with: " + a)
+			fail(c.name, fmt.Errorf("internal error (rerun with --verbose for details): %v", r))
 		}
-	}
+	}()
 
-	if verbose {
-		fmt.Printf("Default context: %v\n", build.Default)
+	if err := c.run(args); err != nil {
+		fail(c.name, err)
 	}
+	os.Exit(0)
+}
 
-	if sourceDir == "" {
-		goLink := "GO.link"
-		si, e := os.Stat(goLink)
-		if e == nil && !si.IsDir() {
-			var by []byte
-			by, e = ioutil.ReadFile(goLink)
-			if e != nil {
-				panic("Must specify --go <go-source-dir-name> option, or put <go-source-dir-name> as the first line of a file named ./GO.link")
-			}
-			m := string(by)
-			if idx := strings.IndexAny(m, "\r\n"); idx == -1 {
-				goLink = m
-			} else {
-				goLink = m[0:idx]
-			}
-			si, e = os.Stat(goLink)
-		}
-		if e != nil || !si.IsDir() {
-			panic(fmt.Sprintf("Must specify --go <go-source-dir-name> option, or make %s a symlink (or text file containing the native path) pointing to the golang/go/ source directory", goLink))
-		}
-		sourceDir = goLink
+// Exits per err, first explaining it (if appropriate) to the user.
+func fail(cmd string, err error) {
+	code := exitCode(err)
+	if code == 0 {
+		os.Exit(0)
 	}
-
-	sourceDir = filepath.Join(sourceDir, "src")
-
-	if fi, e := os.Stat(filepath.Join(sourceDir, "go")); e != nil || !fi.IsDir() {
-		if m, e := filepath.Glob(filepath.Join(sourceDir, "*.go")); e != nil || m == nil || len(m) == 0 {
-			panic(fmt.Sprintf("Does not exist or is not a Go source directory: %s;\n%v", sourceDir, m))
-		}
+	prefix := "gostd2joker: "
+	if cmd != "" {
+		prefix = "gostd2joker " + cmd + ": "
 	}
-
-	jokerLibDir := ""
-	if jokerSourceDir != "" && jokerSourceDir != "-" {
-		jokerLibDir = filepath.Join(jokerSourceDir, "std", "go")
-		if replace {
-			if e := os.RemoveAll(jokerLibDir); e != nil {
-				panic(fmt.Sprintf("Unable to effectively 'rm -fr %s'", jokerLibDir))
-			}
-		}
-
-		if !overwrite {
-			if _, e := os.Stat(jokerLibDir); e == nil ||
-				(!strings.Contains(e.Error(), "no such file or directory") &&
-					!strings.Contains(e.Error(), "The system cannot find the file specified.")) {
-				msg := "already exists"
-				if e != nil {
-					msg = e.Error()
-				}
-				panic(fmt.Sprintf("Refusing to populate existing directory %s; please 'rm -fr' first, or specify --overwrite or --replace: %s",
-					jokerLibDir, msg))
-			}
-			if e := os.MkdirAll(jokerLibDir, 0777); e != nil {
-				panic(fmt.Sprintf("Cannot 'mkdir -p %s': %s", jokerLibDir, e.Error()))
-			}
+	fmt.Fprintln(os.Stderr, prefix+err.Error())
+	if code == exitUsage {
+		if cmd == "" {
+			fmt.Fprintln(os.Stderr, "Run 'gostd2joker help' for usage.")
+		} else {
+			fmt.Fprintf(os.Stderr, "Run 'gostd2joker help %s' for usage.\n", cmd)
 		}
 	}
-
-	err := walkDirs(filepath.Join(sourceDir, "."), mode)
-	if err != nil {
-		panic("Error walking directory " + sourceDir + ": " + fmt.Sprintf("%v", err))
-	}
-
-	sort.Strings(alreadySeen)
-	for _, a := range alreadySeen {
-		fmt.Fprintln(os.Stderr, a)
-	}
-
-	if verbose {
-		/* Output map in sorted order to stabilize for testing. */
-		sortedTypeInfoMap(types,
-			func(t string, ti *typeInfo) {
-				fmt.Printf("TYPE %s:\n", t)
-				fmt.Printf("  %s\n", ti.file)
-			})
-	}
-
-	/* Generate function code snippets in alphabetical order, to stabilize test output in re unsupported types. */
-	sortedFuncInfoMap(qualifiedFunctions,
-		func(f string, v *funcInfo) {
-			genFunction(f, v)
-		})
-
-	var out *bufio.Writer
-	var unbuf_out *os.File
-
-	sortedPackageMap(jokerCode,
-		func(pkgDirUnix string, v codeInfo) {
-			pkgBaseName := path.Base(pkgDirUnix)
-			if jokerLibDir != "" && jokerLibDir != "-" &&
-				(generateEmpty || packagesInfo[pkgDirUnix].nonEmpty) {
-				jf := filepath.Join(jokerLibDir, filepath.FromSlash(pkgDirUnix)+".joke")
-				var e error
-				e = os.MkdirAll(filepath.Dir(jf), 0777)
-				unbuf_out, e = os.Create(jf)
-				check(e)
-				out = bufio.NewWriterSize(unbuf_out, 16384)
-
-				pi := packagesInfo[pkgDirUnix]
-
-				fmt.Fprintf(out,
-					`;;;; Auto-generated by gostd2joker at `+curTimeAndVersion()+`, do not edit!!
-
-(ns
-  ^{:go-imports [%s]
-    :doc "Provides a low-level interface to the %s package."
-    :empty %s}
-  go.%s)
-`,
-					strings.TrimPrefix(packageQuotedImportList(pi.importsAutoGen, " ", false), " "),
-					pkgDirUnix,
-					func() string {
-						if pi.nonEmpty {
-							return "false"
-						} else {
-							return "true"
-						}
-					}(),
-					strings.Replace(pkgDirUnix, "/", ".", -1))
-			}
-			sortedCodeMap(v,
-				func(f string, w string) {
-					if verbose || jokerLibDir == "" {
-						fmt.Printf("JOKER FUNC %s.%s has:%v\n",
-							pkgBaseName, f, w)
-					}
-					if out != nil {
-						out.WriteString(w)
-					}
-				})
-			if out != nil {
-				out.Flush()
-				unbuf_out.Close()
-				out = nil
-			}
-		})
-	sortedPackageMap(goCode,
-		func(pkgDirUnix string, v codeInfo) {
-			pkgBaseName := path.Base(pkgDirUnix)
-			pi := packagesInfo[pkgDirUnix]
-			packagesInfo[pkgDirUnix].hasGoFiles = true
-			pkgDirNative := filepath.FromSlash(pkgDirUnix)
-
-			if jokerLibDir != "" && jokerLibDir != "-" &&
-				(generateEmpty || packagesInfo[pkgDirUnix].nonEmpty) {
-				gf := filepath.Join(jokerLibDir, pkgDirNative,
-					pkgBaseName+"_native.go")
-				var e error
-				e = os.MkdirAll(filepath.Dir(gf), 0777)
-				check(e)
-				unbuf_out, e = os.Create(gf)
-				check(e)
-				out = bufio.NewWriterSize(unbuf_out, 16384)
-
-				importCore := ""
-				if _, f := pi.importsNative[pkgDirUnix]; f {
-					importCore = `
-	. "github.com/candid82/joker/core"`
-				}
-
-				fmt.Fprintf(out,
-					`// Auto-generated by gostd2joker at `+curTimeAndVersion()+`, do not edit!!
-
-package %s
-
-import (%s%s
-)
-`,
-					pkgBaseName,
-					packageQuotedImportList(pi.importsNative, "\n\t", true),
-					importCore)
-			}
-			sortedCodeMap(v,
-				func(f string, w string) {
-					if verbose || jokerLibDir == "" {
-						fmt.Printf("GO FUNC %s.%s has:%v\n",
-							pkgBaseName, f, w)
-					}
-					if out != nil {
-						out.WriteString(w)
-					}
-				})
-			if out != nil {
-				out.Flush()
-				unbuf_out.Close()
-				out = nil
-			}
-		})
-
-	if jokerSourceDir != "" && jokerSourceDir != "-" {
-		var packagesArray = []string{} // Relative package pathnames in alphabetical order
-		var dotJokeArray = []string{}  // Relative package pathnames in alphabetical order
-
-		sortedPackagesInfo(packagesInfo,
-			func(p string, i *packageInfo) {
-				if !generateEmpty && !i.nonEmpty {
-					return
-				}
-				if i.hasGoFiles {
-					packagesArray = append(packagesArray, p)
-				}
-				dotJokeArray = append(dotJokeArray, p)
-			})
-		updateJokerMain(packagesArray, filepath.Join(jokerSourceDir, "main.go"))
-		updateCoreDotJoke(dotJokeArray, filepath.Join(jokerSourceDir, "core", "data", "core.joke"))
-		updateGenerateSTD(packagesArray, filepath.Join(jokerSourceDir, "std", "generate-std.joke"))
-	}
-
-	if verbose || summary {
-		fmt.Printf("ABENDs:")
-		printAbends(abends)
-		fmt.Printf("\nTotals: types=%d functions=%d methods=%d (%s%%) standalone=%d (%s%%) generated=%d (%s%%)\n",
-			len(types), len(qualifiedFunctions)+methods, methods,
-			pct(methods, len(qualifiedFunctions)+methods),
-			len(qualifiedFunctions), pct(len(qualifiedFunctions), len(qualifiedFunctions)+methods),
-			generatedFunctions, pct(generatedFunctions, len(qualifiedFunctions)))
-	}
-
-	os.Exit(0)
-}
-
-func pct(i, j int) string {
-	if j == 0 {
-		return "--"
-	}
-	return fmt.Sprintf("%0.2f", (float64(i)/float64(j))*100.0)
-}
-
-func init() {
-	nonEmptyLineRegexp = regexp.MustCompile(`(?m)^(.)`)
-	abendRegexp = regexp.MustCompile(`ABEND([0-9]+)`)
+	os.Exit(code)
 }
@@ -13,6 +13,74 @@ RC=0
 ./gostd2joker --no-timestamp -v --go tests/small 2>&1 | grep -v '^Default context:' > $GOENV/small.gold
 git diff --quiet -u $GOENV/small.gold || { echo >&2 "FAILED: small test"; RC=1; $EXIT; }
 
+./gostd2joker --no-timestamp -v --errors-as-exceptions --typed-errors --recover-panics --go tests/small 2>&1 | grep -v '^Default context:' > $GOENV/small-errors.gold
+git diff --quiet -u $GOENV/small-errors.gold || { echo >&2 "FAILED: small errors test"; RC=1; $EXIT; }
+
+./gostd2joker --no-timestamp -v --out-param encoding/json.Indent:dst --go tests/out 2>&1 | grep -v '^Default context:' > $GOENV/out.gold
+git diff --quiet -u $GOENV/out.gold || { echo >&2 "FAILED: out-params test"; RC=1; $EXIT; }
+
+./gostd2joker --no-timestamp -v --out-param encoding/json.Indent:dst --pointer-refs --go tests/out 2>&1 | grep -v '^Default context:' > $GOENV/out-refs.gold
+git diff --quiet -u $GOENV/out-refs.gold || { echo >&2 "FAILED: out-params refs test"; RC=1; $EXIT; }
+
+./gostd2joker --no-timestamp -v --async net/url.Parse --go tests/small 2>&1 | grep -v '^Default context:' > $GOENV/small-async.gold
+git diff --quiet -u $GOENV/small-async.gold || { echo >&2 "FAILED: small async test"; RC=1; $EXIT; }
+
+./gostd2joker --no-timestamp -v --pointer-refs --go tests/small 2>&1 | grep -v '^Default context:' > $GOENV/small-refs.gold
+git diff --quiet -u $GOENV/small-refs.gold || { echo >&2 "FAILED: small pointer-refs test"; RC=1; $EXIT; }
+
+./gostd2joker --no-timestamp -v --round-trip --go tests/small 2>&1 | grep -v '^Default context:' > $GOENV/small-round-trip.gold
+git diff --quiet -u $GOENV/small-round-trip.gold || { echo >&2 "FAILED: small round-trip test"; RC=1; $EXIT; }
+
+./gostd2joker --no-timestamp -v --lazy-slices --go tests/small 2>&1 | grep -v '^Default context:' > $GOENV/small-lazy.gold
+git diff --quiet -u $GOENV/small-lazy.gold || { echo >&2 "FAILED: small lazy-slices test"; RC=1; $EXIT; }
+
+./gostd2joker --no-timestamp -v --raw-results --go tests/small 2>&1 | grep -v '^Default context:' > $GOENV/small-raw.gold
+git diff --quiet -u $GOENV/small-raw.gold || { echo >&2 "FAILED: small raw-results test"; RC=1; $EXIT; }
+
+./gostd2joker --no-timestamp -v --max-depth 1 --go tests/small 2>&1 | grep -v '^Default context:' > $GOENV/small-depth.gold
+git diff --quiet -u $GOENV/small-depth.gold || { echo >&2 "FAILED: small max-depth test"; RC=1; $EXIT; }
+
+{ ./gostd2joker report --go tests/small; ./gostd2joker list --go tests/small; ./gostd2joker list functions --go tests/small net/url; } > $GOENV/report.gold 2>&1
+git diff --quiet -u $GOENV/report.gold || { echo >&2 "FAILED: report test"; RC=1; $EXIT; }
+
+./gostd2joker report --diagnostics=json --go tests/small > $GOENV/diagnostics.gold 2>&1
+git diff --quiet -u $GOENV/diagnostics.gold || { echo >&2 "FAILED: diagnostics test"; RC=1; $EXIT; }
+
+./gostd2joker report --go tests/small --save $GOENV/small-result.json > /dev/null 2>&1
+{ ./gostd2joker explain; ./gostd2joker explain --go tests/small ABEND883 net/url.Parse 124; ./gostd2joker explain --result $GOENV/small-result.json 401; } > $GOENV/explain.gold 2>&1
+rm -f $GOENV/small-result.json
+git diff --quiet -u $GOENV/explain.gold || { echo >&2 "FAILED: explain test"; RC=1; $EXIT; }
+
+{ ./gostd2joker unlock --go tests/small; ./gostd2joker unlock --go tests/big --top 3 net/http; } > $GOENV/unlock.gold 2>&1
+git diff --quiet -u $GOENV/unlock.gold || { echo >&2 "FAILED: unlock test"; RC=1; $EXIT; }
+
+./gostd2joker coverage --no-timestamp --go tests/small > $GOENV/coverage.gold 2>&1
+git diff --quiet -u $GOENV/coverage.gold || { echo >&2 "FAILED: coverage test"; RC=1; $EXIT; }
+
+./gostd2joker coverage --no-timestamp --format json --go tests/small net/url > $GOENV/coverage-json.gold 2>&1
+git diff --quiet -u $GOENV/coverage-json.gold || { echo >&2 "FAILED: coverage json test"; RC=1; $EXIT; }
+
+./gostd2joker coverage --no-timestamp --format html --go tests/small --output $GOENV/coverage-html.gold
+git diff --quiet -u $GOENV/coverage-html.gold || { echo >&2 "FAILED: coverage html test"; RC=1; $EXIT; }
+
+./gostd2joker report --go tests/diff/old --save $GOENV/diff-old.json > /dev/null 2>&1
+git diff --quiet -u $GOENV/diff-old.json || { echo >&2 "FAILED: report --save test"; RC=1; $EXIT; }
+
+{ ./gostd2joker diff $GOENV/diff-old.json tests/diff/new; ./gostd2joker diff tests/diff/new tests/diff/old; } > $GOENV/diff.gold 2>&1
+git diff --quiet -u $GOENV/diff.gold || { echo >&2 "FAILED: diff test"; RC=1; $EXIT; }
+
+mkdir -p $GOENV/bad-joker
+: > $GOENV/bad-joker/main.go # Lacking the rest of a Joker tree
+{
+    ./gostd2joker --no-timestamp --go tests/small --replace --joker $GOENV/bad-joker; echo "exit $?"
+    ./gostd2joker report --go tests/small --bogus; echo "exit $?"
+    ./gostd2joker bogus; echo "exit $?"
+    ./gostd2joker list --go tests/small nopkg; echo "exit $?"
+    ./gostd2joker report --go tests/nonexistent; echo "exit $?"
+} > $GOENV/exit-codes.gold 2>&1
+rm -fr $GOENV/bad-joker
+git diff --quiet -u $GOENV/exit-codes.gold || { echo >&2 "FAILED: exit codes test"; RC=1; $EXIT; }
+
 rm -fr $GOENV/joker
 cp -pr tests/joker.orig $GOENV/joker
 ./gostd2joker --no-timestamp -v --go tests/big --replace --joker $GOENV/joker 2>&1 | grep -v '^Default context:' > $GOENV/big.gold
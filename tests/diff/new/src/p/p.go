@@ -0,0 +1,20 @@
+// Package p's new version, differing from tests/diff/old's in each way that
+// 'gostd2joker diff' reports.
+package p
+
+type Mode int
+
+const (
+	Read Mode = iota + 1
+	Write
+)
+
+func Same(renamed int) string { return "" }
+
+func Arity(a, b string) string { return a }
+
+func Unlocked() string { return "" }
+
+func Blocked() map[string]int { return nil }
+
+func Added() string { return "" }
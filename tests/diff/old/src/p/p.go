@@ -0,0 +1,20 @@
+// Package p's old version, differing from tests/diff/new's in each way that
+// 'gostd2joker diff' reports.
+package p
+
+type Mode int
+
+const (
+	Read Mode = iota
+	Write
+)
+
+func Same(a int) string { return "" }
+
+func Gone() string { return "" }
+
+func Arity(a string) string { return a }
+
+func Unlocked() map[string]int { return nil }
+
+func Blocked() string { return "" }
@@ -3,8 +3,10 @@
 package main
 
 import ( // Imports added by gostd2joker
+	_ "github.com/candid82/joker/std/go/gostd"
 	_ "github.com/candid82/joker/std/go/net"
 	_ "github.com/candid82/joker/std/go/net/http"
+	_ "github.com/candid82/joker/std/go/net/http/httputil"
 	_ "github.com/candid82/joker/std/go/net/mail"
 	_ "github.com/candid82/joker/std/go/net/rpc"
 	_ "github.com/candid82/joker/std/go/net/textproto"
@@ -4,16 +4,25 @@ package http
 
 import (
 	_http "net/http"
+	_reflect "reflect"
 	. "github.com/candid82/joker/core"
+	gostd "github.com/candid82/joker/std/go/gostd"
 )
 
-// func error(w ABEND884(unrecognized type ResponseWriter at: tests/big/src/net/http/server.go:1973:14), error string, code int) Object {
+func detectContentType(data Object) Object {
+	_res := _http.DetectContentType(gostd.BytesOf(data))
+	return MakeString(_res)
+}
+
+// func error_(w ABEND884(unrecognized type ResponseWriter at: tests/big/src/net/http/server.go:1973:14), error string, code int) Object {
 // 	_http.Error(w, error, code)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
 
-// func fileServer(root ABEND884(unrecognized type FileSystem at: tests/big/src/net/http/fs.go:713:22)) Object {
-// 	return _http.FileServer(root)
+// func fileServer(root Object) Object {
+// 	defer gostd.ReleaseEval()()
+// 	_root := ABEND754(cannot implement net/http.FileSystem via a Joker fn){gostd.CallbackOf(root)}
+// 	return _http.FileServer(_root)
 // }
 
 // func get(url string) Object {
@@ -28,7 +37,7 @@ import (
 // 		_map1.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*resp).ProtoMajor)))
 // 		_map1.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*resp).ProtoMinor)))
 // 		_map1.Add(MakeKeyword("Header"), (*resp).Header)
-// 		_map1.Add(MakeKeyword("Body"), (*resp).Body)
+// 		_map1.Add(MakeKeyword("Body"), gostd.MakeGoObject((*resp).Body))
 // 		_map1.Add(MakeKeyword("ContentLength"), MakeInt(int((*resp).ContentLength)))
 // 		_vec2 := EmptyVector
 // 		for _, _elem2 := range (*resp).TransferEncoding {
@@ -47,7 +56,7 @@ import (
 // 			_map3.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*(*resp).Request).ProtoMajor)))
 // 			_map3.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*(*resp).Request).ProtoMinor)))
 // 			_map3.Add(MakeKeyword("Header"), (*(*resp).Request).Header)
-// 			_map3.Add(MakeKeyword("Body"), (*(*resp).Request).Body)
+// 			_map3.Add(MakeKeyword("Body"), gostd.MakeGoObject((*(*resp).Request).Body))
 // 			_map3.Add(MakeKeyword("GetBody"), (*(*resp).Request).GetBody)
 // 			_map3.Add(MakeKeyword("ContentLength"), MakeInt(int((*(*resp).Request).ContentLength)))
 // 			_vec4 := EmptyVector
@@ -70,26 +79,33 @@ import (
 // 		} else {
 // 			_obj_map3 = NIL
 // 		}
-// 		_map1.Add(MakeKeyword("Request"), _obj_map3)
+// 		_map1.Add(MakeKeyword("Request"), gostd.TagGoType(_obj_map3, "net/http.Request"))
 // 		_map1.Add(MakeKeyword("TLS"), (*(*resp).TLS))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	_res = _res.Conjoin(_obj_map1)
+// 	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/http.Response"))
 // 	_res = _res.Conjoin(func () Object { if (err) == nil { return NIL } else { return MakeError(err) } }())
 // 	return _res
 // }
 
-// func handle(pattern string, handler ABEND884(unrecognized type Handler at: tests/big/src/net/http/server.go:2401:37)) Object {
-// 	_http.Handle(pattern, handler)
-// 	...ABEND675: TODO...
-// }
+func handle(pattern string, handler Object) Object {
+	defer gostd.ReleaseEval()()
+	_handler := handlerAdapter{gostd.CallbackOf(handler)}
+	_http.Handle(pattern, _handler)
+	return NIL
+}
 
-// func handleFunc(pattern string, handler ABEND882(unrecognized Expr type *ast.FuncType at: tests/big/src/net/http/server.go:2406:41)) Object {
-// 	_http.HandleFunc(pattern, handler)
-// 	...ABEND675: TODO...
-// }
+func handleFunc(pattern string, handler Object) Object {
+	defer gostd.ReleaseEval()()
+	_handlerFn := gostd.CallbackOf(handler)
+	_handler := func(_a1 _http.ResponseWriter, _a2 *_http.Request) {
+		gostd.Call(_handlerFn, gostd.MakeGoObject(_a1), gostd.MakeGoObject(_a2))
+	}
+	_http.HandleFunc(pattern, _handler)
+	return NIL
+}
 
 // func head(url string) Object {
 // 	resp, err := _http.Head(url)
@@ -103,7 +119,7 @@ import (
 // 		_map1.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*resp).ProtoMajor)))
 // 		_map1.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*resp).ProtoMinor)))
 // 		_map1.Add(MakeKeyword("Header"), (*resp).Header)
-// 		_map1.Add(MakeKeyword("Body"), (*resp).Body)
+// 		_map1.Add(MakeKeyword("Body"), gostd.MakeGoObject((*resp).Body))
 // 		_map1.Add(MakeKeyword("ContentLength"), MakeInt(int((*resp).ContentLength)))
 // 		_vec2 := EmptyVector
 // 		for _, _elem2 := range (*resp).TransferEncoding {
@@ -122,7 +138,7 @@ import (
 // 			_map3.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*(*resp).Request).ProtoMajor)))
 // 			_map3.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*(*resp).Request).ProtoMinor)))
 // 			_map3.Add(MakeKeyword("Header"), (*(*resp).Request).Header)
-// 			_map3.Add(MakeKeyword("Body"), (*(*resp).Request).Body)
+// 			_map3.Add(MakeKeyword("Body"), gostd.MakeGoObject((*(*resp).Request).Body))
 // 			_map3.Add(MakeKeyword("GetBody"), (*(*resp).Request).GetBody)
 // 			_map3.Add(MakeKeyword("ContentLength"), MakeInt(int((*(*resp).Request).ContentLength)))
 // 			_vec4 := EmptyVector
@@ -145,27 +161,44 @@ import (
 // 		} else {
 // 			_obj_map3 = NIL
 // 		}
-// 		_map1.Add(MakeKeyword("Request"), _obj_map3)
+// 		_map1.Add(MakeKeyword("Request"), gostd.TagGoType(_obj_map3, "net/http.Request"))
 // 		_map1.Add(MakeKeyword("TLS"), (*(*resp).TLS))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	_res = _res.Conjoin(_obj_map1)
+// 	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/http.Response"))
 // 	_res = _res.Conjoin(func () Object { if (err) == nil { return NIL } else { return MakeError(err) } }())
 // 	return _res
 // }
 
+func listenAndServe(addr string, handler Object) Object {
+	defer gostd.ReleaseEval()()
+	_handler := handlerAdapter{gostd.CallbackOf(handler)}
+	_res := _http.ListenAndServe(addr, _handler)
+	return func () Object { if (_res) == nil { return NIL } else { return MakeError(_res) } }()
+}
+
+func listenAndServeTLS(addr string, certFile string, keyFile string, handler Object) Object {
+	defer gostd.ReleaseEval()()
+	_handler := handlerAdapter{gostd.CallbackOf(handler)}
+	_res := _http.ListenAndServeTLS(addr, certFile, keyFile, _handler)
+	return func () Object { if (_res) == nil { return NIL } else { return MakeError(_res) } }()
+}
+
 // func maxBytesReader(w ABEND884(unrecognized type ResponseWriter at: tests/big/src/net/http/request.go:1056:23), r ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/request.go:1056:41), n int64) Object {
-// 	return _http.MaxBytesReader(w, r, n)
+// 	_res := _http.MaxBytesReader(w, r, n)
+// 	return gostd.MakeGoObject(_res)
 // }
 
-// func newFileTransport(fs ABEND884(unrecognized type FileSystem at: tests/big/src/net/http/filetransport.go:30:26)) Object {
-// 	return _http.NewFileTransport(fs)
+// func newFileTransport(fs Object) Object {
+// 	defer gostd.ReleaseEval()()
+// 	_fs := ABEND754(cannot implement net/http.FileSystem via a Joker fn){gostd.CallbackOf(fs)}
+// 	return _http.NewFileTransport(_fs)
 // }
 
-// func newRequest(method string, url string, body ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/request.go:792:42)) Object {
-// 	_res1, _res2 := _http.NewRequest(method, url, body)
+// func newRequest(method string, url string, body Object) Object {
+// 	_res1, _res2 := _http.NewRequest(method, url, gostd.ToReader(body))
 // 	_res := EmptyVector
 // 	var _obj_map1 Object
 // 	if _res1 != nil {
@@ -176,7 +209,7 @@ import (
 // 		_map1.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*_res1).ProtoMajor)))
 // 		_map1.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*_res1).ProtoMinor)))
 // 		_map1.Add(MakeKeyword("Header"), (*_res1).Header)
-// 		_map1.Add(MakeKeyword("Body"), (*_res1).Body)
+// 		_map1.Add(MakeKeyword("Body"), gostd.MakeGoObject((*_res1).Body))
 // 		_map1.Add(MakeKeyword("GetBody"), (*_res1).GetBody)
 // 		_map1.Add(MakeKeyword("ContentLength"), MakeInt(int((*_res1).ContentLength)))
 // 		_vec2 := EmptyVector
@@ -203,7 +236,7 @@ import (
 // 			_map3.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*(*_res1).Response).ProtoMajor)))
 // 			_map3.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*(*_res1).Response).ProtoMinor)))
 // 			_map3.Add(MakeKeyword("Header"), (*(*_res1).Response).Header)
-// 			_map3.Add(MakeKeyword("Body"), (*(*_res1).Response).Body)
+// 			_map3.Add(MakeKeyword("Body"), gostd.MakeGoObject((*(*_res1).Response).Body))
 // 			_map3.Add(MakeKeyword("ContentLength"), MakeInt(int((*(*_res1).Response).ContentLength)))
 // 			_vec4 := EmptyVector
 // 			for _, _elem4 := range (*(*_res1).Response).TransferEncoding {
@@ -219,12 +252,12 @@ import (
 // 		} else {
 // 			_obj_map3 = NIL
 // 		}
-// 		_map1.Add(MakeKeyword("Response"), _obj_map3)
+// 		_map1.Add(MakeKeyword("Response"), gostd.TagGoType(_obj_map3, "net/http.Response"))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	_res = _res.Conjoin(_obj_map1)
+// 	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/http.Request"))
 // 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 // 	return _res
 // }
@@ -236,7 +269,7 @@ import (
 
 // func notFound(w ABEND884(unrecognized type ResponseWriter at: tests/big/src/net/http/server.go:1981:17), r ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/server.go:1981:35)) Object {
 // 	_http.NotFound(w, r)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
 
 // func notFoundHandler() Object {
@@ -260,8 +293,8 @@ func parseHTTPVersion(vers string) Object {
 // 	return _res
 // }
 
-// func post(url string, contentType string, body ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/client.go:748:41)) Object {
-// 	resp, err := _http.Post(url, contentType, body)
+// func post(url string, contentType string, body Object) Object {
+// 	resp, err := _http.Post(url, contentType, gostd.ToReader(body))
 // 	_res := EmptyVector
 // 	var _obj_map1 Object
 // 	if resp != nil {
@@ -272,7 +305,7 @@ func parseHTTPVersion(vers string) Object {
 // 		_map1.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*resp).ProtoMajor)))
 // 		_map1.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*resp).ProtoMinor)))
 // 		_map1.Add(MakeKeyword("Header"), (*resp).Header)
-// 		_map1.Add(MakeKeyword("Body"), (*resp).Body)
+// 		_map1.Add(MakeKeyword("Body"), gostd.MakeGoObject((*resp).Body))
 // 		_map1.Add(MakeKeyword("ContentLength"), MakeInt(int((*resp).ContentLength)))
 // 		_vec2 := EmptyVector
 // 		for _, _elem2 := range (*resp).TransferEncoding {
@@ -291,7 +324,7 @@ func parseHTTPVersion(vers string) Object {
 // 			_map3.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*(*resp).Request).ProtoMajor)))
 // 			_map3.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*(*resp).Request).ProtoMinor)))
 // 			_map3.Add(MakeKeyword("Header"), (*(*resp).Request).Header)
-// 			_map3.Add(MakeKeyword("Body"), (*(*resp).Request).Body)
+// 			_map3.Add(MakeKeyword("Body"), gostd.MakeGoObject((*(*resp).Request).Body))
 // 			_map3.Add(MakeKeyword("GetBody"), (*(*resp).Request).GetBody)
 // 			_map3.Add(MakeKeyword("ContentLength"), MakeInt(int((*(*resp).Request).ContentLength)))
 // 			_vec4 := EmptyVector
@@ -314,13 +347,13 @@ func parseHTTPVersion(vers string) Object {
 // 		} else {
 // 			_obj_map3 = NIL
 // 		}
-// 		_map1.Add(MakeKeyword("Request"), _obj_map3)
+// 		_map1.Add(MakeKeyword("Request"), gostd.TagGoType(_obj_map3, "net/http.Request"))
 // 		_map1.Add(MakeKeyword("TLS"), (*(*resp).TLS))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	_res = _res.Conjoin(_obj_map1)
+// 	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/http.Response"))
 // 	_res = _res.Conjoin(func () Object { if (err) == nil { return NIL } else { return MakeError(err) } }())
 // 	return _res
 // }
@@ -337,7 +370,7 @@ func parseHTTPVersion(vers string) Object {
 // 		_map1.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*resp).ProtoMajor)))
 // 		_map1.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*resp).ProtoMinor)))
 // 		_map1.Add(MakeKeyword("Header"), (*resp).Header)
-// 		_map1.Add(MakeKeyword("Body"), (*resp).Body)
+// 		_map1.Add(MakeKeyword("Body"), gostd.MakeGoObject((*resp).Body))
 // 		_map1.Add(MakeKeyword("ContentLength"), MakeInt(int((*resp).ContentLength)))
 // 		_vec2 := EmptyVector
 // 		for _, _elem2 := range (*resp).TransferEncoding {
@@ -356,7 +389,7 @@ func parseHTTPVersion(vers string) Object {
 // 			_map3.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*(*resp).Request).ProtoMajor)))
 // 			_map3.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*(*resp).Request).ProtoMinor)))
 // 			_map3.Add(MakeKeyword("Header"), (*(*resp).Request).Header)
-// 			_map3.Add(MakeKeyword("Body"), (*(*resp).Request).Body)
+// 			_map3.Add(MakeKeyword("Body"), gostd.MakeGoObject((*(*resp).Request).Body))
 // 			_map3.Add(MakeKeyword("GetBody"), (*(*resp).Request).GetBody)
 // 			_map3.Add(MakeKeyword("ContentLength"), MakeInt(int((*(*resp).Request).ContentLength)))
 // 			_vec4 := EmptyVector
@@ -379,13 +412,13 @@ func parseHTTPVersion(vers string) Object {
 // 		} else {
 // 			_obj_map3 = NIL
 // 		}
-// 		_map1.Add(MakeKeyword("Request"), _obj_map3)
+// 		_map1.Add(MakeKeyword("Request"), gostd.TagGoType(_obj_map3, "net/http.Request"))
 // 		_map1.Add(MakeKeyword("TLS"), (*(*resp).TLS))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	_res = _res.Conjoin(_obj_map1)
+// 	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/http.Response"))
 // 	_res = _res.Conjoin(func () Object { if (err) == nil { return NIL } else { return MakeError(err) } }())
 // 	return _res
 // }
@@ -402,8 +435,8 @@ func parseHTTPVersion(vers string) Object {
 // 	return _http.ProxyURL(fixedURL)
 // }
 
-// func readRequest(b ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/request.go:942:20)) Object {
-// 	_res1, _res2 := _http.ReadRequest(b)
+// func readRequest(b Object) Object {
+// 	_res1, _res2 := _http.ReadRequest(gostd.ToBufioReader(b))
 // 	_res := EmptyVector
 // 	var _obj_map1 Object
 // 	if _res1 != nil {
@@ -414,7 +447,7 @@ func parseHTTPVersion(vers string) Object {
 // 		_map1.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*_res1).ProtoMajor)))
 // 		_map1.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*_res1).ProtoMinor)))
 // 		_map1.Add(MakeKeyword("Header"), (*_res1).Header)
-// 		_map1.Add(MakeKeyword("Body"), (*_res1).Body)
+// 		_map1.Add(MakeKeyword("Body"), gostd.MakeGoObject((*_res1).Body))
 // 		_map1.Add(MakeKeyword("GetBody"), (*_res1).GetBody)
 // 		_map1.Add(MakeKeyword("ContentLength"), MakeInt(int((*_res1).ContentLength)))
 // 		_vec2 := EmptyVector
@@ -441,7 +474,7 @@ func parseHTTPVersion(vers string) Object {
 // 			_map3.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*(*_res1).Response).ProtoMajor)))
 // 			_map3.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*(*_res1).Response).ProtoMinor)))
 // 			_map3.Add(MakeKeyword("Header"), (*(*_res1).Response).Header)
-// 			_map3.Add(MakeKeyword("Body"), (*(*_res1).Response).Body)
+// 			_map3.Add(MakeKeyword("Body"), gostd.MakeGoObject((*(*_res1).Response).Body))
 // 			_map3.Add(MakeKeyword("ContentLength"), MakeInt(int((*(*_res1).Response).ContentLength)))
 // 			_vec4 := EmptyVector
 // 			for _, _elem4 := range (*(*_res1).Response).TransferEncoding {
@@ -457,18 +490,18 @@ func parseHTTPVersion(vers string) Object {
 // 		} else {
 // 			_obj_map3 = NIL
 // 		}
-// 		_map1.Add(MakeKeyword("Response"), _obj_map3)
+// 		_map1.Add(MakeKeyword("Response"), gostd.TagGoType(_obj_map3, "net/http.Response"))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	_res = _res.Conjoin(_obj_map1)
+// 	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/http.Request"))
 // 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 // 	return _res
 // }
 
-// func readResponse(r ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/response.go:148:21), req ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/response.go:148:40)) Object {
-// 	_res1, _res2 := _http.ReadResponse(r, req)
+// func readResponse(r Object, req ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/response.go:148:40)) Object {
+// 	_res1, _res2 := _http.ReadResponse(gostd.ToBufioReader(r), req)
 // 	_res := EmptyVector
 // 	var _obj_map1 Object
 // 	if _res1 != nil {
@@ -479,7 +512,7 @@ func parseHTTPVersion(vers string) Object {
 // 		_map1.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*_res1).ProtoMajor)))
 // 		_map1.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*_res1).ProtoMinor)))
 // 		_map1.Add(MakeKeyword("Header"), (*_res1).Header)
-// 		_map1.Add(MakeKeyword("Body"), (*_res1).Body)
+// 		_map1.Add(MakeKeyword("Body"), gostd.MakeGoObject((*_res1).Body))
 // 		_map1.Add(MakeKeyword("ContentLength"), MakeInt(int((*_res1).ContentLength)))
 // 		_vec2 := EmptyVector
 // 		for _, _elem2 := range (*_res1).TransferEncoding {
@@ -498,7 +531,7 @@ func parseHTTPVersion(vers string) Object {
 // 			_map3.Add(MakeKeyword("ProtoMajor"), MakeInt(int((*(*_res1).Request).ProtoMajor)))
 // 			_map3.Add(MakeKeyword("ProtoMinor"), MakeInt(int((*(*_res1).Request).ProtoMinor)))
 // 			_map3.Add(MakeKeyword("Header"), (*(*_res1).Request).Header)
-// 			_map3.Add(MakeKeyword("Body"), (*(*_res1).Request).Body)
+// 			_map3.Add(MakeKeyword("Body"), gostd.MakeGoObject((*(*_res1).Request).Body))
 // 			_map3.Add(MakeKeyword("GetBody"), (*(*_res1).Request).GetBody)
 // 			_map3.Add(MakeKeyword("ContentLength"), MakeInt(int((*(*_res1).Request).ContentLength)))
 // 			_vec4 := EmptyVector
@@ -521,45 +554,329 @@ func parseHTTPVersion(vers string) Object {
 // 		} else {
 // 			_obj_map3 = NIL
 // 		}
-// 		_map1.Add(MakeKeyword("Request"), _obj_map3)
+// 		_map1.Add(MakeKeyword("Request"), gostd.TagGoType(_obj_map3, "net/http.Request"))
 // 		_map1.Add(MakeKeyword("TLS"), (*(*_res1).TLS))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	_res = _res.Conjoin(_obj_map1)
+// 	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/http.Response"))
 // 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 // 	return _res
 // }
 
 // func redirect(w ABEND884(unrecognized type ResponseWriter at: tests/big/src/net/http/server.go:2020:17), r ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/server.go:2020:35), url string, code int) Object {
 // 	_http.Redirect(w, r, url, code)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
 
 // func redirectHandler(url string, code int) Object {
 // 	return _http.RedirectHandler(url, code)
 // }
 
+// func serve(l ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/server.go:2421:14), handler Object) Object {
+// 	defer gostd.ReleaseEval()()
+// 	_handler := handlerAdapter{gostd.CallbackOf(handler)}
+// 	_res := _http.Serve(l, _handler)
+// 	return func () Object { if (_res) == nil { return NIL } else { return MakeError(_res) } }()
+// }
+
 // func serveContent(w ABEND884(unrecognized type ResponseWriter at: tests/big/src/net/http/fs.go:151:21), req ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/fs.go:151:41), name string, modtime ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/fs.go:151:72), content ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/fs.go:151:91)) Object {
 // 	_http.ServeContent(w, req, name, modtime, content)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
 
 // func serveFile(w ABEND884(unrecognized type ResponseWriter at: tests/big/src/net/http/fs.go:670:18), r ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/fs.go:670:36), name string) Object {
 // 	_http.ServeFile(w, r, name)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
+// }
+
+// func serveTLS(l ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/server.go:2438:17), handler Object, certFile string, keyFile string) Object {
+// 	defer gostd.ReleaseEval()()
+// 	_handler := handlerAdapter{gostd.CallbackOf(handler)}
+// 	_res := _http.ServeTLS(l, _handler, certFile, keyFile)
+// 	return func () Object { if (_res) == nil { return NIL } else { return MakeError(_res) } }()
 // }
 
 // func setCookie(w ABEND884(unrecognized type ResponseWriter at: tests/big/src/net/http/cookie.go:157:18), cookie ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/cookie.go:157:41)) Object {
 // 	_http.SetCookie(w, cookie)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
 
-// func stripPrefix(prefix string, h ABEND884(unrecognized type Handler at: tests/big/src/net/http/server.go:1992:35)) Object {
-// 	return _http.StripPrefix(prefix, h)
+// func stripPrefix(prefix string, h Object) Object {
+// 	defer gostd.ReleaseEval()()
+// 	_h := handlerAdapter{gostd.CallbackOf(h)}
+// 	return _http.StripPrefix(prefix, _h)
 // }
 
-// func timeoutHandler(h ABEND884(unrecognized type Handler at: tests/big/src/net/http/server.go:3106:23), dt ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/server.go:3106:35), msg string) Object {
-// 	return _http.TimeoutHandler(h, dt, msg)
+// func timeoutHandler(h Object, dt ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/server.go:3106:35), msg string) Object {
+// 	defer gostd.ReleaseEval()()
+// 	_h := handlerAdapter{gostd.CallbackOf(h)}
+// 	return _http.TimeoutHandler(_h, dt, msg)
 // }
+
+func init() {
+	gostd.RegisterGetter(_reflect.TypeOf((*_http.Client)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_http.Client)
+		switch _k {
+		case "Transport":
+			return true, gostd.FromGo(_v.Transport)
+		case "CheckRedirect":
+			return true, gostd.FromGo(_v.CheckRedirect)
+		case "Jar":
+			return true, gostd.FromGo(_v.Jar)
+		case "Timeout":
+			return true, gostd.FromGo(_v.Timeout)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_http.Cookie)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_http.Cookie)
+		switch _k {
+		case "Name":
+			return true, MakeString(_v.Name)
+		case "Value":
+			return true, MakeString(_v.Value)
+		case "Path":
+			return true, MakeString(_v.Path)
+		case "Domain":
+			return true, MakeString(_v.Domain)
+		case "Expires":
+			return true, gostd.FromGo(_v.Expires)
+		case "RawExpires":
+			return true, MakeString(_v.RawExpires)
+		case "MaxAge":
+			return true, MakeInt(int(_v.MaxAge))
+		case "Secure":
+			return true, MakeBool(_v.Secure)
+		case "HttpOnly":
+			return true, MakeBool(_v.HttpOnly)
+		case "SameSite":
+			return true, MakeInt(int(_v.SameSite))
+		case "Raw":
+			return true, MakeString(_v.Raw)
+		case "Unparsed":
+			_vec1 := EmptyVector
+			for _, _elem1 := range _v.Unparsed {
+				_vec1 = _vec1.Conjoin(MakeString(_elem1))
+			}
+			return true, _vec1
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_http.ProtocolError)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_http.ProtocolError)
+		switch _k {
+		case "ErrorString":
+			return true, MakeString(_v.ErrorString)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_http.PushOptions)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_http.PushOptions)
+		switch _k {
+		case "Method":
+			return true, MakeString(_v.Method)
+		case "Header":
+			return true, gostd.FromGo(_v.Header)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_http.Request)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_http.Request)
+		switch _k {
+		case "Method":
+			return true, MakeString(_v.Method)
+		case "URL":
+			return true, gostd.FromGo(_v.URL)
+		case "Proto":
+			return true, MakeString(_v.Proto)
+		case "ProtoMajor":
+			return true, MakeInt(int(_v.ProtoMajor))
+		case "ProtoMinor":
+			return true, MakeInt(int(_v.ProtoMinor))
+		case "Header":
+			return true, gostd.FromGo(_v.Header)
+		case "Body":
+			return true, gostd.FromGo(_v.Body)
+		case "GetBody":
+			return true, gostd.FromGo(_v.GetBody)
+		case "ContentLength":
+			return true, MakeInt(int(_v.ContentLength))
+		case "TransferEncoding":
+			_vec1 := EmptyVector
+			for _, _elem1 := range _v.TransferEncoding {
+				_vec1 = _vec1.Conjoin(MakeString(_elem1))
+			}
+			return true, _vec1
+		case "Close":
+			return true, MakeBool(_v.Close)
+		case "Host":
+			return true, MakeString(_v.Host)
+		case "Form":
+			return true, gostd.FromGo(_v.Form)
+		case "PostForm":
+			return true, gostd.FromGo(_v.PostForm)
+		case "MultipartForm":
+			return true, gostd.FromGo(_v.MultipartForm)
+		case "Trailer":
+			return true, gostd.FromGo(_v.Trailer)
+		case "RemoteAddr":
+			return true, MakeString(_v.RemoteAddr)
+		case "RequestURI":
+			return true, MakeString(_v.RequestURI)
+		case "TLS":
+			return true, gostd.FromGo(_v.TLS)
+		case "Cancel":
+			return true, gostd.FromGo(_v.Cancel)
+		case "Response":
+			return true, gostd.FromGo(_v.Response)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_http.Response)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_http.Response)
+		switch _k {
+		case "Status":
+			return true, MakeString(_v.Status)
+		case "StatusCode":
+			return true, MakeInt(int(_v.StatusCode))
+		case "Proto":
+			return true, MakeString(_v.Proto)
+		case "ProtoMajor":
+			return true, MakeInt(int(_v.ProtoMajor))
+		case "ProtoMinor":
+			return true, MakeInt(int(_v.ProtoMinor))
+		case "Header":
+			return true, gostd.FromGo(_v.Header)
+		case "Body":
+			return true, gostd.FromGo(_v.Body)
+		case "ContentLength":
+			return true, MakeInt(int(_v.ContentLength))
+		case "TransferEncoding":
+			_vec1 := EmptyVector
+			for _, _elem1 := range _v.TransferEncoding {
+				_vec1 = _vec1.Conjoin(MakeString(_elem1))
+			}
+			return true, _vec1
+		case "Close":
+			return true, MakeBool(_v.Close)
+		case "Uncompressed":
+			return true, MakeBool(_v.Uncompressed)
+		case "Trailer":
+			return true, gostd.FromGo(_v.Trailer)
+		case "Request":
+			return true, gostd.FromGo(_v.Request)
+		case "TLS":
+			return true, gostd.FromGo(_v.TLS)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_http.Server)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_http.Server)
+		switch _k {
+		case "Addr":
+			return true, MakeString(_v.Addr)
+		case "Handler":
+			return true, gostd.FromGo(_v.Handler)
+		case "TLSConfig":
+			return true, gostd.FromGo(_v.TLSConfig)
+		case "ReadTimeout":
+			return true, gostd.FromGo(_v.ReadTimeout)
+		case "ReadHeaderTimeout":
+			return true, gostd.FromGo(_v.ReadHeaderTimeout)
+		case "WriteTimeout":
+			return true, gostd.FromGo(_v.WriteTimeout)
+		case "IdleTimeout":
+			return true, gostd.FromGo(_v.IdleTimeout)
+		case "MaxHeaderBytes":
+			return true, MakeInt(int(_v.MaxHeaderBytes))
+		case "TLSNextProto":
+			return true, gostd.FromGo(_v.TLSNextProto)
+		case "ConnState":
+			return true, gostd.FromGo(_v.ConnState)
+		case "ErrorLog":
+			return true, gostd.FromGo(_v.ErrorLog)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_http.Transport)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_http.Transport)
+		switch _k {
+		case "Proxy":
+			return true, gostd.FromGo(_v.Proxy)
+		case "DialContext":
+			return true, gostd.FromGo(_v.DialContext)
+		case "Dial":
+			return true, gostd.FromGo(_v.Dial)
+		case "DialTLS":
+			return true, gostd.FromGo(_v.DialTLS)
+		case "TLSClientConfig":
+			return true, gostd.FromGo(_v.TLSClientConfig)
+		case "TLSHandshakeTimeout":
+			return true, gostd.FromGo(_v.TLSHandshakeTimeout)
+		case "DisableKeepAlives":
+			return true, MakeBool(_v.DisableKeepAlives)
+		case "DisableCompression":
+			return true, MakeBool(_v.DisableCompression)
+		case "MaxIdleConns":
+			return true, MakeInt(int(_v.MaxIdleConns))
+		case "MaxIdleConnsPerHost":
+			return true, MakeInt(int(_v.MaxIdleConnsPerHost))
+		case "MaxConnsPerHost":
+			return true, MakeInt(int(_v.MaxConnsPerHost))
+		case "IdleConnTimeout":
+			return true, gostd.FromGo(_v.IdleConnTimeout)
+		case "ResponseHeaderTimeout":
+			return true, gostd.FromGo(_v.ResponseHeaderTimeout)
+		case "ExpectContinueTimeout":
+			return true, gostd.FromGo(_v.ExpectContinueTimeout)
+		case "TLSNextProto":
+			return true, gostd.FromGo(_v.TLSNextProto)
+		case "ProxyConnectHeader":
+			return true, gostd.FromGo(_v.ProxyConnectHeader)
+		case "MaxResponseHeaderBytes":
+			return true, MakeInt(int(_v.MaxResponseHeaderBytes))
+		}
+		return false, nil
+	})
+}
+
+type handlerAdapter struct {
+	fn Callable
+}
+
+func (a handlerAdapter) ServeHTTP(_a1 _http.ResponseWriter, _a2 *_http.Request) {
+	gostd.Call(a.fn, gostd.MakeGoObject(_a1), gostd.MakeGoObject(_a2))
+}
+
+func isGoType(x Object, t string) Object {
+	return MakeBool(gostd.IsGoType(x, t))
+}
+
+func init() {
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Client)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.CloseNotifier)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.ConnState)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Cookie)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.CookieJar)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Dir)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.File)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.FileSystem)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Flusher)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Handler)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.HandlerFunc)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Header)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Hijacker)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.ProtocolError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.PushOptions)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Pusher)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Request)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Response)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.ResponseWriter)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.RoundTripper)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.SameSite)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.ServeMux)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Server)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_http.Transport)(nil)).Elem())
+}
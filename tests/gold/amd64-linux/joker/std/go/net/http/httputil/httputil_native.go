@@ -0,0 +1,124 @@
+// Auto-generated by gostd2joker at (omitted for testing), do not edit!!
+
+package httputil
+
+import (
+	_httputil "net/http/httputil"
+	_reflect "reflect"
+	. "github.com/candid82/joker/core"
+	gostd "github.com/candid82/joker/std/go/gostd"
+)
+
+// func dumpRequest(req ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/httputil/dump.go:191:22), body bool) Object {
+// 	_res1, _res2 := _httputil.DumpRequest(req, body)
+// 	_res := EmptyVector
+// 	_vec1 := EmptyVector
+// 	for _, _elem1 := range _res1 {
+// 		_vec1 = _vec1.Conjoin(MakeInt(int(_elem1)))
+// 	}
+// 	_res = _res.Conjoin(_vec1)
+// 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
+// 	return _res
+// }
+
+// func dumpRequestOut(req ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/httputil/dump.go:66:25), body bool) Object {
+// 	_res1, _res2 := _httputil.DumpRequestOut(req, body)
+// 	_res := EmptyVector
+// 	_vec1 := EmptyVector
+// 	for _, _elem1 := range _res1 {
+// 		_vec1 = _vec1.Conjoin(MakeInt(int(_elem1)))
+// 	}
+// 	_res = _res.Conjoin(_vec1)
+// 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
+// 	return _res
+// }
+
+// func dumpResponse(resp ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/httputil/dump.go:281:24), body bool) Object {
+// 	_res1, _res2 := _httputil.DumpResponse(resp, body)
+// 	_res := EmptyVector
+// 	_vec1 := EmptyVector
+// 	for _, _elem1 := range _res1 {
+// 		_vec1 = _vec1.Conjoin(MakeInt(int(_elem1)))
+// 	}
+// 	_res = _res.Conjoin(_vec1)
+// 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
+// 	return _res
+// }
+
+func newChunkedReader(r Object) Object {
+	_res := _httputil.NewChunkedReader(gostd.ToReader(r))
+	return gostd.MakeGoObject(_res)
+}
+
+func newChunkedWriter(w Object) Object {
+	_res := _httputil.NewChunkedWriter(gostd.ToWriter(w))
+	return gostd.MakeGoObject(_res)
+}
+
+// func newClientConn(c ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/httputil/persist.go:248:22), r Object) Object {
+// 	return _httputil.NewClientConn(c, gostd.ToBufioReader(r))
+// 	ABEND124(no public information returned)
+// }
+
+// func newProxyClientConn(c ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/httputil/persist.go:265:27), r Object) Object {
+// 	return _httputil.NewProxyClientConn(c, gostd.ToBufioReader(r))
+// 	ABEND124(no public information returned)
+// }
+
+// func newServerConn(c ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/http/httputil/persist.go:54:22), r Object) Object {
+// 	return _httputil.NewServerConn(c, gostd.ToBufioReader(r))
+// 	ABEND124(no public information returned)
+// }
+
+// func newSingleHostReverseProxy(target ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/http/httputil/reverseproxy.go:103:39)) Object {
+// 	_res := _httputil.NewSingleHostReverseProxy(target)
+// 	var _obj_map1 Object
+// 	if _res != nil {
+// 		_map1 := EmptyArrayMap()
+// 		_map1.Add(MakeKeyword("Director"), (*_res).Director)
+// 		_map1.Add(MakeKeyword("Transport"), (*_res).Transport)
+// 		_map1.Add(MakeKeyword("FlushInterval"), (*_res).FlushInterval)
+// 		_map1.Add(MakeKeyword("ErrorLog"), (*(*_res).ErrorLog))
+// 		_map1.Add(MakeKeyword("BufferPool"), (*_res).BufferPool)
+// 		_map1.Add(MakeKeyword("ModifyResponse"), (*_res).ModifyResponse)
+// 		_map1.Add(MakeKeyword("ErrorHandler"), (*_res).ErrorHandler)
+// 		_obj_map1 = Object(_map1)
+// 	} else {
+// 		_obj_map1 = NIL
+// 	}
+// 	return gostd.TagGoType(_obj_map1, "net/http/httputil.ReverseProxy")
+// }
+
+func init() {
+	gostd.RegisterGetter(_reflect.TypeOf((*_httputil.ReverseProxy)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_httputil.ReverseProxy)
+		switch _k {
+		case "Director":
+			return true, gostd.FromGo(_v.Director)
+		case "Transport":
+			return true, gostd.FromGo(_v.Transport)
+		case "FlushInterval":
+			return true, gostd.FromGo(_v.FlushInterval)
+		case "ErrorLog":
+			return true, gostd.FromGo(_v.ErrorLog)
+		case "BufferPool":
+			return true, gostd.FromGo(_v.BufferPool)
+		case "ModifyResponse":
+			return true, gostd.FromGo(_v.ModifyResponse)
+		case "ErrorHandler":
+			return true, gostd.FromGo(_v.ErrorHandler)
+		}
+		return false, nil
+	})
+}
+
+func isGoType(x Object, t string) Object {
+	return MakeBool(gostd.IsGoType(x, t))
+}
+
+func init() {
+	gostd.RegisterGoType(_reflect.TypeOf((*_httputil.BufferPool)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_httputil.ClientConn)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_httputil.ReverseProxy)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_httputil.ServerConn)(nil)).Elem())
+}
@@ -4,7 +4,9 @@ package mail
 
 import (
 	_mail "net/mail"
+	_reflect "reflect"
 	. "github.com/candid82/joker/core"
+	gostd "github.com/candid82/joker/std/go/gostd"
 )
 
 func parseAddress(address string) Object {
@@ -19,7 +21,7 @@ func parseAddress(address string) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/mail.Address"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -38,7 +40,7 @@ func parseAddressList(list string) Object {
 		} else {
 			_obj_map2 = NIL
 		}
-		_vec1 = _vec1.Conjoin(_obj_map2)
+		_vec1 = _vec1.Conjoin(gostd.TagGoType(_obj_map2, "net/mail.Address"))
 	}
 	_res = _res.Conjoin(_vec1)
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
@@ -53,19 +55,61 @@ func parseAddressList(list string) Object {
 // 	return _res
 // }
 
-// func readMessage(r ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/mail/message.go:52:20)) Object {
-// 	msg, err := _mail.ReadMessage(r)
+// func readMessage(r Object) Object {
+// 	msg, err := _mail.ReadMessage(gostd.ToReader(r))
 // 	_res := EmptyVector
 // 	var _obj_map1 Object
 // 	if msg != nil {
 // 		_map1 := EmptyArrayMap()
 // 		_map1.Add(MakeKeyword("Header"), (*msg).Header)
-// 		_map1.Add(MakeKeyword("Body"), (*msg).Body)
+// 		_map1.Add(MakeKeyword("Body"), gostd.MakeGoObject((*msg).Body))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	_res = _res.Conjoin(_obj_map1)
+// 	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/mail.Message"))
 // 	_res = _res.Conjoin(func () Object { if (err) == nil { return NIL } else { return MakeError(err) } }())
 // 	return _res
 // }
+
+func init() {
+	gostd.RegisterGetter(_reflect.TypeOf((*_mail.Address)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_mail.Address)
+		switch _k {
+		case "Name":
+			return true, MakeString(_v.Name)
+		case "Address":
+			return true, MakeString(_v.Address)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_mail.AddressParser)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_mail.AddressParser)
+		switch _k {
+		case "WordDecoder":
+			return true, gostd.FromGo(_v.WordDecoder)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_mail.Message)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_mail.Message)
+		switch _k {
+		case "Header":
+			return true, gostd.FromGo(_v.Header)
+		case "Body":
+			return true, gostd.FromGo(_v.Body)
+		}
+		return false, nil
+	})
+}
+
+func isGoType(x Object, t string) Object {
+	return MakeBool(gostd.IsGoType(x, t))
+}
+
+func init() {
+	gostd.RegisterGoType(_reflect.TypeOf((*_mail.Address)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_mail.AddressParser)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_mail.Header)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_mail.Message)(nil)).Elem())
+}
@@ -4,7 +4,9 @@ package net
 
 import (
 	_net "net"
+	_reflect "reflect"
 	. "github.com/candid82/joker/core"
+	gostd "github.com/candid82/joker/std/go/gostd"
 )
 
 func cIDRMask(ones int, bits int) Object {
@@ -137,7 +139,7 @@ func interfaceByIndex(index int) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net.Interface"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -161,7 +163,7 @@ func interfaceByName(name string) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net.Interface"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -181,7 +183,7 @@ func interfaces() Object {
 		}
 		_map2.Add(MakeKeyword("HardwareAddr"), _vec3)
 		_map2.Add(MakeKeyword("Flags"), MakeInt(int(_elem1.Flags)))
-		_vec1 = _vec1.Conjoin(_map2)
+		_vec1 = _vec1.Conjoin(gostd.TagGoType(_map2, "net.Interface"))
 	}
 	_res = _res.Conjoin(_vec1)
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
@@ -314,7 +316,7 @@ func lookupMX(name string) Object {
 		} else {
 			_obj_map2 = NIL
 		}
-		_vec1 = _vec1.Conjoin(_obj_map2)
+		_vec1 = _vec1.Conjoin(gostd.TagGoType(_obj_map2, "net.MX"))
 	}
 	_res = _res.Conjoin(_vec1)
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
@@ -334,7 +336,7 @@ func lookupNS(name string) Object {
 		} else {
 			_obj_map2 = NIL
 		}
-		_vec1 = _vec1.Conjoin(_obj_map2)
+		_vec1 = _vec1.Conjoin(gostd.TagGoType(_obj_map2, "net.NS"))
 	}
 	_res = _res.Conjoin(_vec1)
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
@@ -366,7 +368,7 @@ func lookupSRV(service string, proto string, name string) Object {
 		} else {
 			_obj_map2 = NIL
 		}
-		_vec1 = _vec1.Conjoin(_obj_map2)
+		_vec1 = _vec1.Conjoin(gostd.TagGoType(_obj_map2, "net.SRV"))
 	}
 	_res = _res.Conjoin(_vec1)
 	_res = _res.Conjoin(func () Object { if (err) == nil { return NIL } else { return MakeError(err) } }())
@@ -410,7 +412,7 @@ func parseCIDR(s string) Object {
 	} else {
 		_obj_map2 = NIL
 	}
-	_res = _res.Conjoin(_obj_map2)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map2, "net.IPNet"))
 	_res = _res.Conjoin(func () Object { if (_res3) == nil { return NIL } else { return MakeError(_res3) } }())
 	return _res
 }
@@ -460,7 +462,7 @@ func resolveIPAddr(network string, address string) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net.IPAddr"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -482,7 +484,7 @@ func resolveTCPAddr(network string, address string) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net.TCPAddr"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -504,7 +506,7 @@ func resolveUDPAddr(network string, address string) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net.UDPAddr"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -521,7 +523,7 @@ func resolveUnixAddr(network string, address string) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net.UnixAddr"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -534,3 +536,254 @@ func splitHostPort(hostport string) Object {
 	_res = _res.Conjoin(func () Object { if (err) == nil { return NIL } else { return MakeError(err) } }())
 	return _res
 }
+
+func init() {
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.AddrError)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.AddrError)
+		switch _k {
+		case "Err":
+			return true, MakeString(_v.Err)
+		case "Addr":
+			return true, MakeString(_v.Addr)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.DNSConfigError)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.DNSConfigError)
+		switch _k {
+		case "Err":
+			return true, func () Object { if (_v.Err) == nil { return NIL } else { return MakeError(_v.Err) } }()
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.DNSError)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.DNSError)
+		switch _k {
+		case "Err":
+			return true, MakeString(_v.Err)
+		case "Name":
+			return true, MakeString(_v.Name)
+		case "Server":
+			return true, MakeString(_v.Server)
+		case "IsTimeout":
+			return true, MakeBool(_v.IsTimeout)
+		case "IsTemporary":
+			return true, MakeBool(_v.IsTemporary)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.Dialer)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.Dialer)
+		switch _k {
+		case "Timeout":
+			return true, gostd.FromGo(_v.Timeout)
+		case "Deadline":
+			return true, gostd.FromGo(_v.Deadline)
+		case "LocalAddr":
+			return true, gostd.FromGo(_v.LocalAddr)
+		case "DualStack":
+			return true, MakeBool(_v.DualStack)
+		case "FallbackDelay":
+			return true, gostd.FromGo(_v.FallbackDelay)
+		case "KeepAlive":
+			return true, gostd.FromGo(_v.KeepAlive)
+		case "Resolver":
+			return true, gostd.FromGo(_v.Resolver)
+		case "Cancel":
+			return true, gostd.FromGo(_v.Cancel)
+		case "Control":
+			return true, gostd.FromGo(_v.Control)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.IPAddr)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.IPAddr)
+		switch _k {
+		case "IP":
+			return true, gostd.FromGo(_v.IP)
+		case "Zone":
+			return true, MakeString(_v.Zone)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.IPNet)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.IPNet)
+		switch _k {
+		case "IP":
+			return true, gostd.FromGo(_v.IP)
+		case "Mask":
+			return true, gostd.FromGo(_v.Mask)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.Interface)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.Interface)
+		switch _k {
+		case "Index":
+			return true, MakeInt(int(_v.Index))
+		case "MTU":
+			return true, MakeInt(int(_v.MTU))
+		case "Name":
+			return true, MakeString(_v.Name)
+		case "HardwareAddr":
+			return true, gostd.FromGo(_v.HardwareAddr)
+		case "Flags":
+			return true, MakeInt(int(_v.Flags))
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.ListenConfig)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.ListenConfig)
+		switch _k {
+		case "Control":
+			return true, gostd.FromGo(_v.Control)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.MX)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.MX)
+		switch _k {
+		case "Host":
+			return true, MakeString(_v.Host)
+		case "Pref":
+			return true, MakeInt(int(_v.Pref))
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.NS)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.NS)
+		switch _k {
+		case "Host":
+			return true, MakeString(_v.Host)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.OpError)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.OpError)
+		switch _k {
+		case "Op":
+			return true, MakeString(_v.Op)
+		case "Net":
+			return true, MakeString(_v.Net)
+		case "Source":
+			return true, gostd.FromGo(_v.Source)
+		case "Addr":
+			return true, gostd.FromGo(_v.Addr)
+		case "Err":
+			return true, func () Object { if (_v.Err) == nil { return NIL } else { return MakeError(_v.Err) } }()
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.ParseError)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.ParseError)
+		switch _k {
+		case "Type":
+			return true, MakeString(_v.Type)
+		case "Text":
+			return true, MakeString(_v.Text)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.Resolver)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.Resolver)
+		switch _k {
+		case "PreferGo":
+			return true, MakeBool(_v.PreferGo)
+		case "StrictErrors":
+			return true, MakeBool(_v.StrictErrors)
+		case "Dial":
+			return true, gostd.FromGo(_v.Dial)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.SRV)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.SRV)
+		switch _k {
+		case "Target":
+			return true, MakeString(_v.Target)
+		case "Port":
+			return true, MakeInt(int(_v.Port))
+		case "Priority":
+			return true, MakeInt(int(_v.Priority))
+		case "Weight":
+			return true, MakeInt(int(_v.Weight))
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.TCPAddr)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.TCPAddr)
+		switch _k {
+		case "IP":
+			return true, gostd.FromGo(_v.IP)
+		case "Port":
+			return true, MakeInt(int(_v.Port))
+		case "Zone":
+			return true, MakeString(_v.Zone)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.UDPAddr)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.UDPAddr)
+		switch _k {
+		case "IP":
+			return true, gostd.FromGo(_v.IP)
+		case "Port":
+			return true, MakeInt(int(_v.Port))
+		case "Zone":
+			return true, MakeString(_v.Zone)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_net.UnixAddr)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_net.UnixAddr)
+		switch _k {
+		case "Name":
+			return true, MakeString(_v.Name)
+		case "Net":
+			return true, MakeString(_v.Net)
+		}
+		return false, nil
+	})
+}
+
+func isGoType(x Object, t string) Object {
+	return MakeBool(gostd.IsGoType(x, t))
+}
+
+func init() {
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Addr)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.AddrError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Buffers)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Conn)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.DNSConfigError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.DNSError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Dialer)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Error)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Flags)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.HardwareAddr)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.IP)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.IPAddr)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.IPConn)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.IPMask)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.IPNet)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Interface)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.InvalidAddrError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.ListenConfig)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Listener)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.MX)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.NS)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.OpError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.PacketConn)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.ParseError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.Resolver)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.SRV)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.TCPAddr)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.TCPConn)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.TCPListener)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.UDPAddr)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.UDPConn)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.UnixAddr)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.UnixConn)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.UnixListener)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_net.UnknownNetworkError)(nil)).Elem())
+}
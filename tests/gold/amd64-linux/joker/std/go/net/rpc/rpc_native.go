@@ -4,12 +4,14 @@ package rpc
 
 import (
 	_rpc "net/rpc"
+	_reflect "reflect"
 	. "github.com/candid82/joker/core"
+	gostd "github.com/candid82/joker/std/go/gostd"
 )
 
 // func accept(lis ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/rpc/server.go:692:17)) Object {
 // 	_rpc.Accept(lis)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
 
 func dial(network string, address string) Object {
@@ -38,7 +40,7 @@ func dialHTTPPath(network string, address string, path string) Object {
 
 // func handleHTTP() Object {
 // 	_rpc.HandleHTTP()
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
 
 // func newClient(conn ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/rpc/client.go:193:21)) Object {
@@ -56,12 +58,78 @@ func dialHTTPPath(network string, address string, path string) Object {
 // 	ABEND124(no public information returned)
 // }
 
+func register(rcvr Object) Object {
+	_res := _rpc.Register(gostd.ToGo(rcvr))
+	return func () Object { if (_res) == nil { return NIL } else { return MakeError(_res) } }()
+}
+
+func registerName(name string, rcvr Object) Object {
+	_res := _rpc.RegisterName(name, gostd.ToGo(rcvr))
+	return func () Object { if (_res) == nil { return NIL } else { return MakeError(_res) } }()
+}
+
 // func serveCodec(codec ABEND884(unrecognized type ServerCodec at: tests/big/src/net/rpc/server.go:679:23)) Object {
 // 	_rpc.ServeCodec(codec)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
 
 // func serveConn(conn ABEND882(unrecognized Expr type *ast.SelectorExpr at: tests/big/src/net/rpc/server.go:673:21)) Object {
 // 	_rpc.ServeConn(conn)
-// 	...ABEND675: TODO...
+// 	ABEND675(no results to return)
 // }
+
+func init() {
+	gostd.RegisterGetter(_reflect.TypeOf((*_rpc.Call)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_rpc.Call)
+		switch _k {
+		case "ServiceMethod":
+			return true, MakeString(_v.ServiceMethod)
+		case "Args":
+			return true, gostd.FromGo(_v.Args)
+		case "Reply":
+			return true, gostd.FromGo(_v.Reply)
+		case "Error":
+			return true, func () Object { if (_v.Error) == nil { return NIL } else { return MakeError(_v.Error) } }()
+		case "Done":
+			return true, gostd.FromGo(_v.Done)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_rpc.Request)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_rpc.Request)
+		switch _k {
+		case "ServiceMethod":
+			return true, MakeString(_v.ServiceMethod)
+		case "Seq":
+			return true, gostd.FromGo(_v.Seq)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_rpc.Response)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_rpc.Response)
+		switch _k {
+		case "ServiceMethod":
+			return true, MakeString(_v.ServiceMethod)
+		case "Seq":
+			return true, gostd.FromGo(_v.Seq)
+		case "Error":
+			return true, MakeString(_v.Error)
+		}
+		return false, nil
+	})
+}
+
+func isGoType(x Object, t string) Object {
+	return MakeBool(gostd.IsGoType(x, t))
+}
+
+func init() {
+	gostd.RegisterGoType(_reflect.TypeOf((*_rpc.Call)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_rpc.Client)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_rpc.ClientCodec)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_rpc.Request)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_rpc.Response)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_rpc.Server)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_rpc.ServerCodec)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_rpc.ServerError)(nil)).Elem())
+}
@@ -4,7 +4,9 @@ package textproto
 
 import (
 	_textproto "net/textproto"
+	_reflect "reflect"
 	. "github.com/candid82/joker/core"
+	gostd "github.com/candid82/joker/std/go/gostd"
 )
 
 func dial(network string, addr string) Object {
@@ -20,37 +22,80 @@ func dial(network string, addr string) Object {
 // 	ABEND124(no public information returned)
 // }
 
-// func newReader(r ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/textproto/reader.go:29:18)) Object {
-// 	_res := _textproto.NewReader(r)
+// func newReader(r Object) Object {
+// 	_res := _textproto.NewReader(gostd.ToBufioReader(r))
 // 	var _obj_map1 Object
 // 	if _res != nil {
 // 		_map1 := EmptyArrayMap()
-// 		_map1.Add(MakeKeyword("R"), (*(*_res).R))
+// 		_map1.Add(MakeKeyword("R"), gostd.MakeGoObject((*_res).R))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	return _obj_map1
+// 	return gostd.TagGoType(_obj_map1, "net/textproto.Reader")
 // }
 
-// func newWriter(w ABEND882(unrecognized Expr type *ast.StarExpr at: tests/big/src/net/textproto/writer.go:21:18)) Object {
-// 	_res := _textproto.NewWriter(w)
+// func newWriter(w Object) Object {
+// 	_res := _textproto.NewWriter(gostd.ToBufioWriter(w))
 // 	var _obj_map1 Object
 // 	if _res != nil {
 // 		_map1 := EmptyArrayMap()
-// 		_map1.Add(MakeKeyword("W"), (*(*_res).W))
+// 		_map1.Add(MakeKeyword("W"), gostd.MakeGoObject((*_res).W))
 // 		_obj_map1 = Object(_map1)
 // 	} else {
 // 		_obj_map1 = NIL
 // 	}
-// 	return _obj_map1
+// 	return gostd.TagGoType(_obj_map1, "net/textproto.Writer")
 // }
 
-// func trimBytes(b ABEND882(unrecognized Expr type *ast.ArrayType at: tests/big/src/net/textproto/textproto.go:137:18)) Object {
-// 	_res := _textproto.TrimBytes(b)
-// 	_vec1 := EmptyVector
-// 	for _, _elem1 := range _res {
-// 		_vec1 = _vec1.Conjoin(MakeInt(int(_elem1)))
-// 	}
-// 	return _vec1
-// }
+func trimBytes(b Object) Object {
+	_res := _textproto.TrimBytes(gostd.BytesOf(b))
+	_vec1 := EmptyVector
+	for _, _elem1 := range _res {
+		_vec1 = _vec1.Conjoin(MakeInt(int(_elem1)))
+	}
+	return _vec1
+}
+
+func init() {
+	gostd.RegisterGetter(_reflect.TypeOf((*_textproto.Error)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_textproto.Error)
+		switch _k {
+		case "Code":
+			return true, MakeInt(int(_v.Code))
+		case "Msg":
+			return true, MakeString(_v.Msg)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_textproto.Reader)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_textproto.Reader)
+		switch _k {
+		case "R":
+			return true, gostd.FromGo(_v.R)
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_textproto.Writer)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_textproto.Writer)
+		switch _k {
+		case "W":
+			return true, gostd.FromGo(_v.W)
+		}
+		return false, nil
+	})
+}
+
+func isGoType(x Object, t string) Object {
+	return MakeBool(gostd.IsGoType(x, t))
+}
+
+func init() {
+	gostd.RegisterGoType(_reflect.TypeOf((*_textproto.Conn)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_textproto.Error)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_textproto.MIMEHeader)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_textproto.Pipeline)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_textproto.ProtocolError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_textproto.Reader)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_textproto.Writer)(nil)).Elem())
+}
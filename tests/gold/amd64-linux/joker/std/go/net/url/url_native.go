@@ -4,7 +4,9 @@ package url
 
 import (
 	_url "net/url"
+	_reflect "reflect"
 	. "github.com/candid82/joker/core"
+	gostd "github.com/candid82/joker/std/go/gostd"
 )
 
 func parse(rawurl string) Object {
@@ -26,7 +28,7 @@ func parse(rawurl string) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/url.URL"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -58,7 +60,7 @@ func parseRequestURI(rawurl string) Object {
 	} else {
 		_obj_map1 = NIL
 	}
-	_res = _res.Conjoin(_obj_map1)
+	_res = _res.Conjoin(gostd.TagGoType(_obj_map1, "net/url.URL"))
 	_res = _res.Conjoin(func () Object { if (_res2) == nil { return NIL } else { return MakeError(_res2) } }())
 	return _res
 }
@@ -88,3 +90,55 @@ func queryUnescape(s string) Object {
 // 	return _url.UserPassword(username, password)
 // 	ABEND124(no public information returned)
 // }
+
+func init() {
+	gostd.RegisterGetter(_reflect.TypeOf((*_url.Error)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_url.Error)
+		switch _k {
+		case "Op":
+			return true, MakeString(_v.Op)
+		case "URL":
+			return true, MakeString(_v.URL)
+		case "Err":
+			return true, func () Object { if (_v.Err) == nil { return NIL } else { return MakeError(_v.Err) } }()
+		}
+		return false, nil
+	})
+	gostd.RegisterGetter(_reflect.TypeOf((*_url.URL)(nil)), func(_o interface{}, _k string) (bool, Object) {
+		_v := _o.(*_url.URL)
+		switch _k {
+		case "Scheme":
+			return true, MakeString(_v.Scheme)
+		case "Opaque":
+			return true, MakeString(_v.Opaque)
+		case "User":
+			return true, gostd.FromGo(_v.User)
+		case "Host":
+			return true, MakeString(_v.Host)
+		case "Path":
+			return true, MakeString(_v.Path)
+		case "RawPath":
+			return true, MakeString(_v.RawPath)
+		case "ForceQuery":
+			return true, MakeBool(_v.ForceQuery)
+		case "RawQuery":
+			return true, MakeString(_v.RawQuery)
+		case "Fragment":
+			return true, MakeString(_v.Fragment)
+		}
+		return false, nil
+	})
+}
+
+func isGoType(x Object, t string) Object {
+	return MakeBool(gostd.IsGoType(x, t))
+}
+
+func init() {
+	gostd.RegisterGoType(_reflect.TypeOf((*_url.Error)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_url.EscapeError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_url.InvalidHostError)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_url.URL)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_url.Userinfo)(nil)).Elem())
+	gostd.RegisterGoType(_reflect.TypeOf((*_url.Values)(nil)).Elem())
+}
@@ -0,0 +1,22 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Excerpts of package binary, whose Read's data is an out-param.
+package binary
+
+import "io"
+
+// A ByteOrder specifies how to convert byte slices into
+// unsigned integers.
+type ByteOrder interface {
+	Uint16([]byte) uint16
+	String() string
+}
+
+// Read reads structured binary data from r into data.
+// Data must be a pointer to a fixed-size value or a slice
+// of fixed-size values.
+func Read(r io.Reader, order ByteOrder, data any) error {
+	return nil
+}
@@ -0,0 +1,21 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Excerpts of package json: Unmarshal's v is an interface{} out-param,
+// and Indent's dst (a *bytes.Buffer) may be made one via --out-param.
+package json
+
+import "bytes"
+
+// Unmarshal parses the JSON-encoded data and stores the result
+// in the value pointed to by v. If v is nil or not a pointer,
+// Unmarshal returns an InvalidUnmarshalError.
+func Unmarshal(data []byte, v any) error {
+	return nil
+}
+
+// Indent appends to dst an indented form of the JSON-encoded src.
+func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
+	return nil
+}
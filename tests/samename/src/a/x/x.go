@@ -0,0 +1,9 @@
+// Package x (a/x) shares its name, and that of its type T, with b/x,
+// so 'gostd2joker unlock' and 'coverage' must tell their Ts apart.
+package x
+
+type T map[string]int
+
+func F() T { return nil }
+
+func G() T { return nil }
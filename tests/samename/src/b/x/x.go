@@ -0,0 +1,6 @@
+// Package x (b/x) shares its name, and that of its type T, with a/x.
+package x
+
+type T map[string]bool
+
+func F() T { return nil }
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jcburley/gostd2joker/gen"
+)
+
+/* The unlock command attributes each function that wouldn't be
+/* generated to the (Go) types blocking it, per the Unsupported
+/* diagnostics (counting *T and []T as T), and ranks the types by how
+/* many functions supporting them would unlock: alone (those blocked
+/* by nothing else), and in combination (adding, at each step, the
+/* types that unlock the most functions per type added). Functions
+/* blocked by something other than a type (such as ABEND675,
+/* returning no results) can't be unlocked so, and are merely
+/* counted. */
+
+// A function that wouldn't be generated, and what's blocking it.
+type blocked struct {
+	function string
+	pkg      string
+	types    map[string]bool
+	other    bool // Whether it's also blocked by something other than a type
+}
+
+// Returns the (non-excluded) functions that wouldn't be generated.
+func blockedFunctions(res *gen.Result) []*blocked {
+	bs := []*blocked{}
+	byName := map[string]*blocked{}
+	for _, f := range res.Functions {
+		if f.Generated || f.Excluded {
+			continue
+		}
+		b := &blocked{function: f.Name, pkg: f.Package, types: map[string]bool{}}
+		bs = append(bs, b)
+		byName[f.Name] = b
+	}
+	for _, d := range res.Diagnostics {
+		b, ok := byName[d.Function]
+		if !ok || d.Severity != gen.Unsupported {
+			continue
+		}
+		if d.Type == "" {
+			b.other = true
+		} else {
+			b.types[blockingType(d)] = true
+		}
+	}
+	return bs
+}
+
+type typeRank struct {
+	typ    string
+	alone  int // Functions blocked only by it
+	blocks int // Functions blocked by it (and perhaps others)
+}
+
+func rankAlone(bs []*blocked) []*typeRank {
+	ranks := map[string]*typeRank{}
+	for _, b := range bs {
+		for t, _ := range b.types {
+			r, ok := ranks[t]
+			if !ok {
+				r = &typeRank{typ: t}
+				ranks[t] = r
+			}
+			r.blocks++
+			if len(b.types) == 1 && !b.other {
+				r.alone++
+			}
+		}
+	}
+	rs := []*typeRank{}
+	for _, r := range ranks {
+		rs = append(rs, r)
+	}
+	sort.Slice(rs,
+		func(i, j int) bool {
+			if rs[i].alone != rs[j].alone {
+				return rs[i].alone > rs[j].alone
+			}
+			if rs[i].blocks != rs[j].blocks {
+				return rs[i].blocks > rs[j].blocks
+			}
+			return rs[i].typ < rs[j].typ
+		})
+	return rs
+}
+
+type unlockStep struct {
+	types    []string // Added at this step
+	unlocked int      // Functions thereby unlocked
+	total    int      // Functions unlocked by this and the preceding steps
+}
+
+// Greedily chooses up to max sets of types to support, each unlocking
+// the most functions per type added to those already chosen.
+func rankCombined(bs []*blocked, max int) []*unlockStep {
+	type group struct {
+		types []string // Still unsupported, in order
+		n     int      // Functions blocked by exactly these
+	}
+	chosen := map[string]bool{}
+	steps := []*unlockStep{}
+	total := 0
+	for len(steps) < max {
+		groups := map[string]*group{}
+		for _, b := range bs {
+			if b.other {
+				continue
+			}
+			ts := []string{}
+			for t, _ := range b.types {
+				if !chosen[t] {
+					ts = append(ts, t)
+				}
+			}
+			if len(ts) == 0 {
+				continue // Already unlocked
+			}
+			sort.Strings(ts)
+			k := strings.Join(ts, "\x00")
+			if g, ok := groups[k]; ok {
+				g.n++
+			} else {
+				groups[k] = &group{ts, 1}
+			}
+		}
+
+		var best []string
+		bestGain := 0
+		for _, c := range groups {
+			adding := map[string]bool{}
+			for _, t := range c.types {
+				adding[t] = true
+			}
+			gain := 0
+			for _, g := range groups {
+				if subset(g.types, adding) {
+					gain += g.n
+				}
+			}
+			better := best == nil || gain*len(best) > bestGain*len(c.types) // More functions per type
+			if !better && gain*len(best) == bestGain*len(c.types) {
+				better = gain > bestGain || (gain == bestGain && strings.Join(c.types, " ") < strings.Join(best, " "))
+			}
+			if better {
+				best, bestGain = c.types, gain
+			}
+		}
+		if best == nil {
+			break
+		}
+		for _, t := range best {
+			chosen[t] = true
+		}
+		total += bestGain
+		steps = append(steps, &unlockStep{best, bestGain, total})
+	}
+	return steps
+}
+
+func subset(ts []string, of map[string]bool) bool {
+	for _, t := range ts {
+		if !of[t] {
+			return false
+		}
+	}
+	return true
+}
+
+func runUnlock(args []string) error {
+	c := findCommand("unlock")
+	o := &options{}
+	top := 10
+	fs := newFlagSet(c, o)
+	fs.IntVar(&top, "top", top, "")
+	args, err := parseReportFlagSet(c, fs, o, args)
+	if err != nil {
+		return err
+	}
+	if top <= 0 {
+		return usageErrorf("invalid value %d for --top: must be positive", top)
+	}
+	if err := o.setUp(); err != nil {
+		return err
+	}
+	res, err := o.run()
+	if err != nil {
+		return err
+	}
+
+	bs := blockedFunctions(res)
+	byPackage := map[string][]*blocked{}
+	for _, b := range bs {
+		byPackage[b.pkg] = append(byPackage[b.pkg], b)
+	}
+	if len(args) == 0 {
+		printUnlockRanking("Overall", bs, top)
+		for _, p := range res.Packages {
+			if len(byPackage[p.Path]) != 0 {
+				fmt.Println()
+				printUnlockRanking("Package "+p.Path, byPackage[p.Path], top)
+			}
+		}
+		return nil
+	}
+	found := map[string]bool{}
+	for _, p := range res.Packages {
+		found[p.Path] = true
+	}
+	for _, p := range args {
+		if !found[p] {
+			return usageErrorf("no package %s found", p)
+		}
+	}
+	for i, p := range args {
+		if i > 0 {
+			fmt.Println()
+		}
+		printUnlockRanking("Package "+p, byPackage[p], top)
+	}
+	return nil
+}
+
+func printUnlockRanking(title string, bs []*blocked, top int) {
+	other := 0
+	for _, b := range bs {
+		if b.other {
+			other++
+		}
+	}
+	alone := rankAlone(bs)
+	fmt.Printf("%s: %d function(s) not generated, blocked by %d type(s)", title, len(bs), len(alone))
+	if other != 0 {
+		fmt.Printf("; %d also (or only) by something else", other)
+	}
+	fmt.Println()
+	if len(alone) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "\nAlone:\nRANK\tTYPE\tUNLOCKS\tBLOCKS")
+	for i, r := range alone {
+		if i == top {
+			break
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\n", i+1, r.typ, r.alone, r.blocks)
+	}
+	w.Flush()
+
+	fmt.Fprintln(w, "\nIn combination:\nSTEP\tTYPES ADDED\tUNLOCKS\tTOTAL")
+	for i, s := range rankCombined(bs, top) {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d (%s%%)\n", i+1, strings.Join(s.types, ", "), s.unlocked, s.total, pct(s.total, len(bs)))
+	}
+	w.Flush()
+}
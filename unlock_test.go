@@ -0,0 +1,31 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUnlockTypesSharingPackageName(t *testing.T) {
+	o := &options{}
+	o.GoDir = filepath.Join("tests", "samename")
+	if err := o.setUp(); err != nil {
+		t.Fatal(err)
+	}
+	res, err := o.run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int{}
+	for _, r := range rankAlone(blockedFunctions(res)) {
+		got[r.typ] = r.alone
+	}
+	want := map[string]int{"a/x.T": 2, "b/x.T": 1}
+	if len(got) != len(want) {
+		t.Errorf("got %v, not %v", got, want)
+	}
+	for typ, n := range want {
+		if got[typ] != n {
+			t.Errorf("%s unlocks %d function(s), not %d", typ, got[typ], n)
+		}
+	}
+}